@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jeff-roche/biome/src/services"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff -b <biome-name> (-b <biome-name> | --env-file <file>) [flags]",
+	Short: "Compare two biomes, or a biome with a dotenv file",
+	Long: `Compare the merged config of two biomes, including the AWS profile and commands, or compare
+	the variables of a biome with a dotenv file written by biome save
+	Modes are 'keys' (which variables are set), 'config' (the unresolved config, no AWS calls) and
+	'values' (the resolved values, secrets are hashed). Exits with a status of 1 if there are differences
+	Supported formats are 'text' and 'json'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		biomeNames, _ := cmd.Flags().GetStringArray("biome")
+		envFile, _ := cmd.Flags().GetString("env-file")
+		mode, _ := cmd.Flags().GetString("mode")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format != "text" && format != "json" {
+			log.Fatalf("unknown format '%s', expected one of text or json\n", format)
+		}
+
+		params, err := getParams(cmd)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		biomeService.SetParams(params)
+
+		var diff *services.BiomeDiff
+		switch {
+		case envFile != "" && len(biomeNames) == 1:
+			// A dotenv file only has values to compare
+			if !cmd.Flags().Changed("mode") {
+				mode = services.DIFF_VALUES
+			}
+
+			diff, err = biomeService.DiffBiomeWithDotenv(biomeNames[0], envFile, mode)
+		case envFile == "" && len(biomeNames) == 2:
+			diff, err = biomeService.DiffBiomes(biomeNames[0], biomeNames[1], mode)
+		default:
+			log.Fatalln("specify two biomes with -b, or one biome and a dotenv file with --env-file")
+		}
+
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if format == "json" {
+			out, err := json.MarshalIndent(diff, "", "  ")
+			if err != nil {
+				log.Fatalln(err)
+			}
+
+			fmt.Println(string(out))
+		} else {
+			printDiffText(diff)
+		}
+
+		if diff.Changed() {
+			os.Exit(1)
+		}
+	},
+}
+
+// printDiffText will print the differences in a unified diff style, unchanged variables are only counted
+func printDiffText(diff *services.BiomeDiff) {
+	fmt.Printf("--- %s\n", diff.Left)
+	fmt.Printf("+++ %s\n", diff.Right)
+
+	if diff.AwsProfile != nil && diff.AwsProfile.Change != services.DIFF_UNCHANGED {
+		fmt.Println("aws_profile:")
+		printDiffValue("-", diff.AwsProfile.Left)
+		printDiffValue("+", diff.AwsProfile.Right)
+	}
+
+	if len(diff.Commands) > 0 {
+		fmt.Println("commands:")
+		for _, line := range diff.Commands {
+			fmt.Printf("%s  %s\n", diffPrefix(line.Change), line.Text)
+		}
+	}
+
+	counts := make(map[string]int)
+
+	fmt.Println("environment:")
+	for _, entry := range diff.Environment {
+		counts[entry.Change]++
+
+		switch entry.Change {
+		case services.DIFF_REMOVED:
+			printDiffEnv("-", entry.Name, entry.Left)
+		case services.DIFF_ADDED:
+			printDiffEnv("+", entry.Name, entry.Right)
+		case services.DIFF_CHANGED:
+			printDiffEnv("-", entry.Name, entry.Left)
+			printDiffEnv("+", entry.Name, entry.Right)
+		}
+	}
+
+	fmt.Printf("%d added, %d removed, %d changed, %d unchanged\n",
+		counts[services.DIFF_ADDED], counts[services.DIFF_REMOVED], counts[services.DIFF_CHANGED], counts[services.DIFF_UNCHANGED])
+}
+
+func printDiffValue(prefix string, val *string) {
+	if val != nil {
+		fmt.Printf("%s  %s\n", prefix, *val)
+	}
+}
+
+// printDiffEnv will print a variable as NAME=value, or just the name when only the keys are compared
+func printDiffEnv(prefix string, name string, val *string) {
+	if val == nil {
+		fmt.Printf("%s  %s\n", prefix, name)
+		return
+	}
+
+	fmt.Printf("%s  %s=%s\n", prefix, name, *val)
+}
+
+func diffPrefix(change string) string {
+	switch change {
+	case services.DIFF_ADDED:
+		return "+"
+	case services.DIFF_REMOVED:
+		return "-"
+	}
+
+	return " "
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringArrayP("biome", "b", nil, "a biome to compare, given twice to compare two biomes")
+	diffCmd.Flags().String("env-file", "", "a dotenv file to compare the biome with")
+	diffCmd.Flags().StringP("mode", "m", services.DIFF_CONFIG, "what to compare (keys, config or values), values is the default with --env-file")
+	diffCmd.Flags().StringP("format", "o", "text", "the output format (text or json)")
+	addParamsFlag(diffCmd)
+}
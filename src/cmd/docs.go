@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/jeff-roche/biome/src/services"
+	"github.com/spf13/cobra"
+)
+
+// docsCmd represents the docs command
+var docsCmd = &cobra.Command{
+	Use:   "docs -b <biome-name> [flags]",
+	Short: "Generate a reference of every variable a biome sets",
+	Long: `Generate a Markdown or HTML reference of every variable a biome sets after inheritance, with its
+	description, example, setter, whether it is required or deprecated and the biomes it is inherited from
+	Nothing is resolved so no AWS calls are made and no values are prompted for
+	Supported formats are 'markdown' and 'html'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		biomeName, _ := cmd.Flags().GetString("biome")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format != "markdown" && format != "html" {
+			log.Fatalf("unknown format '%s', expected one of markdown or html\n", format)
+		}
+
+		if err := biomeService.LoadBiomeFromDefaults(biomeName); err != nil {
+			log.Fatalln(err)
+		}
+
+		docs, err := biomeService.DocumentBiome()
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if format == "html" {
+			err = renderDocsHtml(os.Stdout, docs)
+		} else {
+			err = renderDocsMarkdown(os.Stdout, docs)
+		}
+
+		if err != nil {
+			log.Fatalln(err)
+		}
+	},
+}
+
+// docsFuncs are the helpers shared by the Markdown and HTML templates
+var docsFuncs = map[string]interface{}{
+	"join": strings.Join,
+	"source": func(v services.VariableDocs) string {
+		source := formatSource(v.File, v.Line)
+		if v.Local {
+			source += " (local)"
+		}
+
+		return source
+	},
+	"relative": relativeToCD,
+	"yesno": func(b bool) string {
+		if b {
+			return "yes"
+		}
+
+		return "no"
+	},
+}
+
+const docsMarkdownTemplate = `# {{.Name}}
+{{- if .Description}}
+
+{{.Description}}
+{{- end}}
+
+- **Source:** {{code (relative .File)}}
+{{- if .Parents}}
+- **Inherits from:** {{codeList .Parents}}
+{{- end}}
+{{- if .Tags}}
+- **Tags:** {{join .Tags ", "}}
+{{- end}}
+{{- if .AwsProfile}}
+- **AWS profile:** {{code .AwsProfile}}
+{{- end}}
+{{- if .LoadEnv}}
+- **Env files:** {{codeList .LoadEnv}}
+{{- end}}
+{{- if .Sources}}
+- **Sources:** {{codeList .Sources}}
+{{- end}}
+
+## Variables
+
+| Variable | Setter | Required | Description |
+| --- | --- | --- | --- |
+{{- range .Variables}}
+| {{code .Name}}{{if .Deprecated}} (deprecated){{end}} | {{code .Setter}} | {{yesno .Required}} | {{cell .Description}} |
+{{- end}}
+{{range .Variables}}
+### {{code .Name}}
+{{- if .Deprecated}}
+
+> **Deprecated**{{if .DeprecationMessage}}: {{.DeprecationMessage}}{{end}}
+{{- end}}
+{{- if .Description}}
+
+{{.Description}}
+{{- end}}
+
+- **Setter:** {{code .Setter}}{{if .Mode}} ({{.Mode}}){{end}}
+{{- if .Secret}}
+- **Secret:** yes
+{{- else}}
+- **Value:** {{code .Config}}
+{{- end}}
+- **Required:** {{yesno .Required}}
+{{- if .Example}}
+- **Example:** {{code .Example}}
+{{- end}}
+- **Declared in:** {{code .Biome}} ({{source .}})
+{{- if .Overrides}}
+- **Overrides:** {{codeList .Overrides}}
+{{- end}}
+{{end}}`
+
+const docsHtmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+<ul>
+<li><strong>Source:</strong> <code>{{relative .File}}</code></li>
+{{- if .Parents}}
+<li><strong>Inherits from:</strong> {{range $i, $p := .Parents}}{{if $i}}, {{end}}<code>{{$p}}</code>{{end}}</li>
+{{- end}}
+{{- if .Tags}}
+<li><strong>Tags:</strong> {{join .Tags ", "}}</li>
+{{- end}}
+{{- if .AwsProfile}}
+<li><strong>AWS profile:</strong> <code>{{.AwsProfile}}</code></li>
+{{- end}}
+{{- if .LoadEnv}}
+<li><strong>Env files:</strong> {{range $i, $f := .LoadEnv}}{{if $i}}, {{end}}<code>{{$f}}</code>{{end}}</li>
+{{- end}}
+{{- if .Sources}}
+<li><strong>Sources:</strong> {{range $i, $s := .Sources}}{{if $i}}, {{end}}<code>{{$s}}</code>{{end}}</li>
+{{- end}}
+</ul>
+<h2>Variables</h2>
+<table>
+<thead><tr><th>Variable</th><th>Setter</th><th>Required</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .Variables}}
+<tr><td><a href="#{{.Name}}"><code>{{.Name}}</code></a>{{if .Deprecated}} (deprecated){{end}}</td><td><code>{{.Setter}}</code></td><td>{{yesno .Required}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- range .Variables}}
+<h3 id="{{.Name}}"><code>{{.Name}}</code></h3>
+{{- if .Deprecated}}
+<p><strong>Deprecated</strong>{{if .DeprecationMessage}}: {{.DeprecationMessage}}{{end}}</p>
+{{- end}}
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+<ul>
+<li><strong>Setter:</strong> <code>{{.Setter}}</code>{{if .Mode}} ({{.Mode}}){{end}}</li>
+{{- if .Secret}}
+<li><strong>Secret:</strong> yes</li>
+{{- else}}
+<li><strong>Value:</strong> <code>{{.Config}}</code></li>
+{{- end}}
+<li><strong>Required:</strong> {{yesno .Required}}</li>
+{{- if .Example}}
+<li><strong>Example:</strong> <code>{{.Example}}</code></li>
+{{- end}}
+<li><strong>Declared in:</strong> <code>{{.Biome}}</code> ({{source .}})</li>
+{{- if .Overrides}}
+<li><strong>Overrides:</strong> {{range $i, $o := .Overrides}}{{if $i}}, {{end}}<code>{{$o}}</code>{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`
+
+// renderDocsMarkdown will write the biome docs as Markdown
+func renderDocsMarkdown(w io.Writer, docs *services.BiomeDocs) error {
+	funcs := template.FuncMap{
+		"code":     markdownCode,
+		"codeList": markdownCodeList,
+		"cell":     markdownCell,
+	}
+
+	for name, fn := range docsFuncs {
+		funcs[name] = fn
+	}
+
+	tmpl, err := template.New("docs").Funcs(funcs).Parse(docsMarkdownTemplate)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, docs)
+}
+
+// renderDocsHtml will write the biome docs as an HTML page, every value is escaped
+func renderDocsHtml(w io.Writer, docs *services.BiomeDocs) error {
+	tmpl, err := htmltemplate.New("docs").Funcs(docsFuncs).Parse(docsHtmlTemplate)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, docs)
+}
+
+// markdownCode will wrap the text in a code span, using a longer fence when the text has backticks
+func markdownCode(text string) string {
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return fmt.Sprintf("%s %s %s", fence, text, fence)
+	}
+
+	return fence + text + fence
+}
+
+func markdownCodeList(items []string) string {
+	codes := make([]string, 0, len(items))
+	for _, item := range items {
+		codes = append(codes, markdownCode(item))
+	}
+
+	return strings.Join(codes, ", ")
+}
+
+// markdownCell will keep the text on one line and escape the pipes so it stays in its table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "\n", " ")
+	return strings.ReplaceAll(text, "|", "\\|")
+}
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+
+	docsCmd.Flags().StringP("biome", "b", "", "the name of the biome to document")
+	docsCmd.MarkFlagRequired("biome")
+	docsCmd.Flags().StringP("format", "o", "markdown", "the output format (markdown or html)")
+}
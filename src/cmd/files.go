@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jeff-roche/biome/src/lib/fileio"
+	"github.com/jeff-roche/biome/src/repos"
+	"github.com/spf13/cobra"
+)
+
+// filesCmd represents the files command
+var filesCmd = &cobra.Command{
+	Use:   "files",
+	Short: "List the config files that are searched for biomes",
+	Long: `List the config files that are searched for biomes in precedence order
+	along with why each file is searched and whether it exists
+	Local override files (.biome.local.yaml) are listed after the file they override when they exist`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, searchPath := range biomeService.ConfigSearchPaths() {
+			status := "missing"
+			if fileio.FileExists(searchPath.Path) {
+				status = "found"
+			}
+
+			fmt.Printf("%-8s %-12s %s\n", status, searchPath.Source, searchPath.Path)
+
+			for _, localPath := range repos.LocalConfigPaths(searchPath.Path) {
+				if fileio.FileExists(localPath) {
+					fmt.Printf("%-8s %-12s %s\n", "found", "local", localPath)
+					break
+				}
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(filesCmd)
+}
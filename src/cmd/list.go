@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jeff-roche/biome/src/services"
+	"github.com/spf13/cobra"
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the biomes in the config files",
+	Long: `List every biome in the discovered config files with its source file, parents, AWS profile and tags
+	Use --tag to only list the biomes with a tag, repeat it to require several tags
+	Supported formats are 'table' and 'json'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		tags, _ := cmd.Flags().GetStringArray("tag")
+
+		biomes, err := biomeService.ListBiomes(tags)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		switch format {
+		case "table":
+			printBiomeTable(biomes)
+		case "json":
+			out, err := json.MarshalIndent(biomes, "", "  ")
+			if err != nil {
+				log.Fatalln(err)
+			}
+
+			fmt.Println(string(out))
+		default:
+			log.Fatalf("unknown format '%s', expected one of table or json\n", format)
+		}
+	},
+}
+
+// printBiomeTable will print the biomes as an aligned table
+func printBiomeTable(biomes []services.BiomeSummary) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPARENTS\tAWS PROFILE\tTAGS\tSOURCE\tDESCRIPTION")
+
+	for _, biome := range biomes {
+		parents := strings.Join(biome.Parents, ", ")
+		if biome.Error != "" {
+			parents = "error: " + biome.Error
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			biome.Name, orDash(parents), orDash(biome.AwsProfile), orDash(strings.Join(biome.Tags, ",")), formatSource(biome.File, biome.Line), biome.Description)
+	}
+
+	w.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().StringP("format", "o", "table", "the output format (table or json)")
+	listCmd.Flags().StringArray("tag", nil, "only list the biomes with this tag, can be repeated")
+}
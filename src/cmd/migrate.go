@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/spf13/cobra"
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [files...]",
+	Short: "Rewrite config files in the current config format",
+	Long: `Rewrite config files written for an older version of the config format in the current format
+	Comments and the order of keys are kept. The discovered config files are migrated when no files are given
+	Use --check to list the changes without writing them, it exits with a non-zero status if any file needs migrating`,
+	Run: func(cmd *cobra.Command, args []string) {
+		check, _ := cmd.Flags().GetBool("check")
+
+		migrations, err := biomeService.MigrateConfig(args)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if len(migrations) == 0 {
+			fmt.Println("no config files found")
+			return
+		}
+
+		outdated := 0
+		for _, migration := range migrations {
+			if !migration.Changed() {
+				fmt.Printf("%s: up to date\n", migration.File)
+				continue
+			}
+
+			outdated++
+
+			if check {
+				fmt.Printf("%s: needs migrating to version %d\n", migration.File, types.CONFIG_VERSION)
+			} else if err := migration.Write(); err != nil {
+				log.Fatalln(err)
+			} else {
+				fmt.Printf("%s: migrated to version %d\n", migration.File, types.CONFIG_VERSION)
+			}
+
+			for _, change := range migration.Changes {
+				fmt.Printf("  line %d: %s\n", change.Line, change.Message)
+			}
+		}
+
+		if check && outdated > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().Bool("check", false, "list the changes without writing them")
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// addParamsFlag will add the flag used to supply the params of a biome
+func addParamsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArrayP("param", "p", nil, "set a param of the biome (name=value), can be repeated")
+}
+
+// getParams will parse the params supplied with the params flag
+func getParams(cmd *cobra.Command) (map[string]string, error) {
+	values, _ := cmd.Flags().GetStringArray("param")
+
+	params := make(map[string]string, len(values))
+	for _, val := range values {
+		name, paramVal, found := strings.Cut(val, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid param '%s', expected name=value", val)
+		}
+
+		params[name] = paramVal
+	}
+
+	return params, nil
+}
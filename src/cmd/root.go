@@ -22,6 +22,14 @@ var rootCmd = &cobra.Command{
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		configFiles, _ := cmd.Flags().GetStringSlice("config")
+		biomeService.SetConfigFiles(configFiles)
+
+		if strict, _ := cmd.Flags().GetBool("strict"); strict {
+			biomeService.EnableStrictParsing()
+		}
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -39,6 +47,8 @@ func init() {
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
+	rootCmd.PersistentFlags().StringSlice("config", nil, "config file(s) to use instead of searching the default locations")
+	rootCmd.PersistentFlags().Bool("strict", false, "fail on any problem in the config files instead of skipping invalid biomes")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
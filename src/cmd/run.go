@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/jeff-roche/biome/src/lib/cmdr"
 	"github.com/spf13/cobra"
@@ -9,17 +11,38 @@ import (
 
 // runCmd represents the run command
 var runCmd = &cobra.Command{
-	Use:   "run -b <biome-name> [flags] [...cmd]",
+	Use:   "run -b <biome-name> [-p name=value] [flags] [...cmd]",
 	Short: "Run any cli command in the provided biome",
 	Long:  "Run any cli command in the provided biome",
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		biomeName, _ := cmd.Flags().GetString("biome")
+		cleanEnv, _ := cmd.Flags().GetBool("clean-env")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		params, err := getParams(cmd)
+		if err != nil {
+			log.Fatalln(err)
+		}
 
 		if err := biomeService.LoadBiomeFromDefaults(biomeName); err != nil {
 			log.Fatalln(err)
 		}
 
+		biomeService.SetParams(params)
+
+		if cleanEnv {
+			biomeService.EnableCleanEnv()
+		}
+
+		if dryRun {
+			if err := printDryRun(biomeName, args); err != nil {
+				log.Fatalln(err)
+			}
+
+			return
+		}
+
 		if err := biomeService.ActivateBiome(); err != nil {
 			log.Fatalln(err)
 		}
@@ -31,9 +54,34 @@ var runCmd = &cobra.Command{
 	},
 }
 
+// printDryRun will show the host variables that would be dropped and the command that would be run
+func printDryRun(biomeName string, args []string) error {
+	dropped, err := biomeService.DroppedHostEnvs()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("biome '%s' drops %d host variable(s):\n", biomeName, len(dropped))
+	for _, env := range dropped {
+		note := env.Reason
+		if env.Replaced {
+			note += ", set by the biome"
+		}
+
+		fmt.Printf("  %s (%s)\n", env.Name, note)
+	}
+
+	fmt.Printf("would run: %s\n", strings.Join(args, " "))
+
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(runCmd)
 
 	runCmd.Flags().StringP("biome", "b", "", "the name of the biome to configure")
 	runCmd.MarkFlagRequired("biome")
+	addParamsFlag(runCmd)
+	runCmd.Flags().Bool("clean-env", false, "only pass the allowed host variables (PATH, HOME, TERM, ...) and the biome's variables to the command")
+	runCmd.Flags().Bool("dry-run", false, "show the host variables that would be dropped without running anything")
 }
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"fmt"
 	"log"
 
 	"github.com/spf13/cobra"
@@ -16,13 +15,18 @@ var saveCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		biomeName, _ := cmd.Flags().GetString("biome")
 		fileName, _ := cmd.Flags().GetString("file")
-		fmt.Println("LLAMA")
-		fmt.Println(biomeName)
+
+		params, err := getParams(cmd)
+		if err != nil {
+			log.Fatalln(err)
+		}
 
 		if err := biomeService.LoadBiomeFromDefaults(biomeName); err != nil {
 			log.Fatalln(err)
 		}
 
+		biomeService.SetParams(params)
+
 		if err := biomeService.ActivateBiome(); err != nil {
 			log.Fatalln(err)
 		}
@@ -40,4 +44,5 @@ func init() {
 	saveCmd.Flags().StringP("file", "f", ".env", "set the output file name")
 	saveCmd.Flags().StringP("biome", "b", "", "the name of the biome to configure")
 	saveCmd.MarkFlagRequired("biome")
+	addParamsFlag(saveCmd)
 }
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/jeff-roche/biome/src/lib/schema"
+	"github.com/jeff-roche/biome/src/lib/setters"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for .biome.yaml files",
+	Long: `Print the JSON Schema for .biome.yaml files
+	Editors can use the schema to validate and autocomplete biome configs`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		out, err := json.MarshalIndent(schema.Generate(setters.SetterDefinitions), "", "  ")
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		fmt.Println(string(out))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
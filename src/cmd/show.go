@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/jeff-roche/biome/src/services"
+	"github.com/spf13/cobra"
+)
+
+// showCmd represents the show command
+var showCmd = &cobra.Command{
+	Use:   "show -b <biome-name> [-p name=value] [flags]",
+	Short: "Show the merged config of a biome and where each variable comes from",
+	Long: `Show the config of a biome after inheritance with the biome, file, line and setter of every variable
+	Values are resolved by running the setters and secrets are redacted unless --reveal is passed
+	Use --no-resolve to show the config of each variable without contacting AWS or prompting for values
+	Supported formats are 'text' and 'json'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		biomeName, _ := cmd.Flags().GetString("biome")
+		format, _ := cmd.Flags().GetString("format")
+		reveal, _ := cmd.Flags().GetBool("reveal")
+		noResolve, _ := cmd.Flags().GetBool("no-resolve")
+
+		if format != "text" && format != "json" {
+			log.Fatalf("unknown format '%s', expected one of text or json\n", format)
+		}
+
+		params, err := getParams(cmd)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if err := biomeService.LoadBiomeFromDefaults(biomeName); err != nil {
+			log.Fatalln(err)
+		}
+
+		biomeService.SetParams(params)
+
+		details, err := biomeService.ShowBiome(services.ShowOptions{Resolve: !noResolve, Reveal: reveal})
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if format == "json" {
+			out, err := json.MarshalIndent(details, "", "  ")
+			if err != nil {
+				log.Fatalln(err)
+			}
+
+			fmt.Println(string(out))
+			return
+		}
+
+		printBiomeDetails(details)
+	},
+}
+
+// printBiomeDetails will print the biome's settings followed by a table of its variables
+func printBiomeDetails(details *services.BiomeDetails) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	printSetting := func(name string, val string) {
+		if val != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", name, val)
+		}
+	}
+
+	printSetting("name", details.Name)
+	printSetting("description", details.Description)
+	printSetting("source", formatSource(details.File, details.Line))
+	printSetting("inherits from", strings.Join(details.Parents, ", "))
+	printSetting("tags", strings.Join(details.Tags, ", "))
+	if details.ProfileFile != "" {
+		printSetting("aws_profile", fmt.Sprintf("%s (local: %s)", details.AwsProfile, relativeToCD(details.ProfileFile)))
+	} else {
+		printSetting("aws_profile", details.AwsProfile)
+	}
+	printSetting("load_env", strings.Join(details.LoadEnv, ", "))
+	printSetting("sources", strings.Join(details.Sources, ", "))
+	printSetting("unset", strings.Join(details.Unset, ", "))
+
+	if details.Isolate {
+		printSetting("isolate", "true")
+		printSetting("allow_env", strings.Join(details.AllowEnv, ", "))
+	}
+
+	w.Flush()
+
+	if len(details.Params) > 0 || len(details.Unresolved) > 0 {
+		names := make([]string, 0, len(details.Params))
+		for name := range details.Params {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		fmt.Println("params:")
+		for _, name := range names {
+			fmt.Printf("  %s=%s\n", name, details.Params[name])
+		}
+
+		for _, name := range details.Unresolved {
+			fmt.Printf("  %s (unresolved)\n", name)
+		}
+	}
+
+	if len(details.Commands) > 0 {
+		fmt.Println("commands:")
+		for _, command := range details.Commands {
+			fmt.Printf("  %s\n", command)
+		}
+	}
+
+	fmt.Println()
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVALUE\tSETTER\tMODE\tBIOME\tSOURCE")
+
+	for _, env := range details.Environment {
+		biome := env.Biome
+		if env.Local {
+			biome += " (local)"
+		}
+
+		// Variables from a file source already point at the file
+		source := formatSource(env.File, env.Line)
+		if env.From != "" && env.From != types.SOURCE_FILE+" "+env.File {
+			source += fmt.Sprintf(" (%s)", env.From)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			env.Name, env.Value, env.Setter, orDash(env.Mode), biome, source)
+	}
+
+	w.Flush()
+}
+
+// formatSource will format a file and line relative to the current directory
+func formatSource(file string, line int) string {
+	if file == "" {
+		return "-"
+	}
+
+	if line > 0 {
+		return fmt.Sprintf("%s:%d", relativeToCD(file), line)
+	}
+
+	return relativeToCD(file)
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+
+	showCmd.Flags().StringP("biome", "b", "", "the name of the biome to show")
+	showCmd.MarkFlagRequired("biome")
+	addParamsFlag(showCmd)
+	showCmd.Flags().StringP("format", "o", "text", "the output format (text or json)")
+	showCmd.Flags().Bool("reveal", false, "show the values of secrets instead of redacting them")
+	showCmd.Flags().Bool("no-resolve", false, "show the config of each variable without running the setters (no AWS calls or prompts)")
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeff-roche/biome/src/services"
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate every biome in the config files without resolving any values",
+	Long: `Validate every biome in the config files without resolving any values
+	Exits with a non-zero status if any problems are found. No AWS calls are made
+	Supported formats are 'text', 'json' and 'github' (GitHub Actions annotations)`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+
+		biomeService.EnableStrictParsing()
+		report := biomeService.ValidateConfig()
+
+		switch format {
+		case "text":
+			printValidationText(report)
+		case "json":
+			out, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				log.Fatalln(err)
+			}
+
+			fmt.Println(string(out))
+		case "github":
+			printValidationGithub(report)
+		default:
+			log.Fatalf("unknown format '%s', expected one of text, json or github\n", format)
+		}
+
+		if !report.Valid() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringP("format", "o", "text", "the output format (text, json or github)")
+}
+
+func printValidationText(report services.ValidationReport) {
+	for _, problem := range report.Problems {
+		fmt.Println(formatProblemLocation(problem) + problem.Message)
+	}
+
+	if report.Valid() {
+		fmt.Printf("%d biome(s) in %d file(s) are valid\n", len(report.Biomes), len(report.Files))
+	} else {
+		fmt.Printf("%d problem(s) found\n", len(report.Problems))
+	}
+}
+
+// printValidationGithub will print the problems as GitHub Actions workflow commands so they show up as annotations
+func printValidationGithub(report services.ValidationReport) {
+	for _, problem := range report.Problems {
+		var props []string
+
+		if problem.File != "" {
+			props = append(props, "file="+escapeGithubProperty(relativeToCD(problem.File)))
+		}
+
+		if problem.Line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", problem.Line))
+		}
+
+		if problem.Column > 0 {
+			props = append(props, fmt.Sprintf("col=%d", problem.Column))
+		}
+
+		fmt.Printf("::error %s::%s\n", strings.Join(props, ","), escapeGithubData(problem.Message))
+	}
+}
+
+func formatProblemLocation(problem services.ValidationProblem) string {
+	switch {
+	case problem.File == "":
+		return ""
+	case problem.Line == 0:
+		return problem.File + ": "
+	case problem.Column == 0:
+		return fmt.Sprintf("%s:%d: ", problem.File, problem.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d: ", problem.File, problem.Line, problem.Column)
+	}
+}
+
+// relativeToCD will make the path relative to the current directory if it is inside of it
+func relativeToCD(fpath string) string {
+	cd, err := os.Getwd()
+	if err != nil {
+		return fpath
+	}
+
+	rel, err := filepath.Rel(cd, fpath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return fpath
+	}
+
+	return rel
+}
+
+func escapeGithubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeGithubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -1,8 +1,12 @@
 package cmdr
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
+	"time"
 )
 
 func Run(cmdStr string, args ...string) error {
@@ -18,3 +22,67 @@ func Run(cmdStr string, args ...string) error {
 
 	return nil
 }
+
+// Output will run the command and return what it writes to stdout
+//
+// Stdin and stderr are passed through so the command can still prompt and report errors, env
+// (NAME=value) is added to the environment. The command is killed if it runs for longer than
+// the timeout, a timeout of 0 waits forever. A command with a timeout runs in its own process
+// group so anything it starts (sh -c "sleep 5 && ...") is killed with it. On Unix that group
+// can't read the terminal so it gets no stdin, and Ctrl-C kills it as it doesn't reach it otherwise
+func Output(timeout time.Duration, env []string, cmdStr string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+
+	cmd := exec.Command(cmdStr, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	if timeout > 0 {
+		setProcessGroup(cmd)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var expired <-chan time.Time
+	interrupted := make(chan os.Signal, 1)
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		expired = timer.C
+
+		signal.Notify(interrupted, os.Interrupt)
+		defer signal.Stop(interrupted)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return "", err
+		}
+	case <-expired:
+		killProcessGroup(cmd)
+		<-done
+
+		return "", fmt.Errorf("timed out after %s", timeout)
+	case <-interrupted:
+		killProcessGroup(cmd)
+		<-done
+
+		return "", fmt.Errorf("interrupted")
+	}
+
+	return stdout.String(), nil
+}
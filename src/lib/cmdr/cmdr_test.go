@@ -0,0 +1,67 @@
+package cmdr
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutput(t *testing.T) {
+	t.Run("should return the output of the command with the extra environment", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("the test commands need sh")
+		}
+
+		// Act
+		out, err := Output(time.Second, []string{"BIOME_TEST_CMDR=hello"}, "sh", "-c", "echo $BIOME_TEST_CMDR")
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "hello\n", out)
+	})
+
+	t.Run("should kill the processes the command started when it times out", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("the test commands need sh")
+		}
+
+		// Assemble
+		start := time.Now()
+
+		// Act
+		_, err := Output(100*time.Millisecond, nil, "sh", "-c", "sleep 5 && echo hi")
+
+		// Assert
+		assert.EqualError(t, err, "timed out after 100ms")
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("should not give the terminal to a command with a timeout", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("the test commands need sh")
+		}
+
+		// Assemble
+		// Stdin that never ends, the command would wait on it until the timeout
+		rd, wr, err := os.Pipe()
+		assert.Nil(t, err)
+
+		stdin := os.Stdin
+		os.Stdin = rd
+		t.Cleanup(func() {
+			os.Stdin = stdin
+			rd.Close()
+			wr.Close()
+		})
+
+		// Act
+		out, err := Output(5*time.Second, nil, "sh", "-c", "cat; echo done")
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "done\n", out)
+	})
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package cmdr
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup will start the command in a new process group
+//
+// A background process group that reads the terminal is stopped (SIGTTIN) until it is killed,
+// so the command gets no stdin and reads EOF instead
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Stdin = nil
+}
+
+// killProcessGroup will kill the command and every process in its group
+func killProcessGroup(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package cmdr
+
+import "os/exec"
+
+// setProcessGroup is a no-op on Windows, the command is killed on its own
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup will kill the command
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
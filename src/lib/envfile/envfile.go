@@ -0,0 +1,197 @@
+package envfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FORMAT_DOTENV = "dotenv" // KEY=value lines
+	FORMAT_JSON   = "json"   // An object of keys and values
+	FORMAT_YAML   = "yaml"   // A mapping of keys and values
+)
+
+var Formats = []string{FORMAT_DOTENV, FORMAT_JSON, FORMAT_YAML}
+
+// Variable is an environment variable read from an env file
+type Variable struct {
+	Name  string
+	Value string
+	Line  int // The line the variable is set on
+}
+
+// ParseError is a problem reading an env file
+type ParseError struct {
+	File    string
+	Line    int // 0 when the line isn't known
+	Message string
+}
+
+func (e ParseError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", e.File, e.Message)
+}
+
+// DetectFormat will work out the format of the file from its extension, any file that isn't JSON or YAML is a dotenv file
+func DetectFormat(fpath string) string {
+	switch strings.ToLower(filepath.Ext(fpath)) {
+	case ".json":
+		return FORMAT_JSON
+	case ".yaml", ".yml":
+		return FORMAT_YAML
+	}
+
+	return FORMAT_DOTENV
+}
+
+// Read will read the variables from the file in the order they are first set
+//
+// When no format is given it is detected from the file's extension. Values in JSON and YAML files
+// need to be strings, numbers or booleans. Problems are reported as a ParseError with the file and line
+func Read(fpath string, format string) ([]Variable, error) {
+	contents, err := os.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "" {
+		format = DetectFormat(fpath)
+	}
+
+	var vars []Variable
+	var parseErr *ParseError
+
+	switch format {
+	case FORMAT_DOTENV:
+		vars, parseErr = parseDotenv(string(contents))
+	case FORMAT_JSON:
+		vars, parseErr = parseJson(contents)
+	case FORMAT_YAML:
+		vars, parseErr = parseYaml(contents)
+	default:
+		return nil, fmt.Errorf("unknown env file format '%s', expected one of %s", format, strings.Join(Formats, ", "))
+	}
+
+	if parseErr != nil {
+		parseErr.File = fpath
+		return nil, *parseErr
+	}
+
+	return vars, nil
+}
+
+// parseDotenv will parse the file with godotenv, which reads a variable from every line that isn't blank or a comment
+func parseDotenv(contents string) ([]Variable, *ParseError) {
+	values, err := godotenv.Unmarshal(contents)
+
+	var vars []Variable
+	index := make(map[string]int)
+
+	for i, line := range strings.Split(contents, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Parse the line on its own to find the variable it sets, or the line that failed
+		lineVals, lineErr := godotenv.Unmarshal(line)
+		if lineErr != nil {
+			return nil, &ParseError{Line: i + 1, Message: lineErr.Error()}
+		}
+
+		// The last assignment of a variable wins
+		for name := range lineVals {
+			if n, exists := index[name]; exists {
+				vars[n].Line = i + 1
+				continue
+			}
+
+			index[name] = len(vars)
+			vars = append(vars, Variable{Name: name, Value: values[name], Line: i + 1})
+		}
+	}
+
+	if err != nil {
+		return nil, &ParseError{Message: err.Error()}
+	}
+
+	return vars, nil
+}
+
+// parseJson will parse a JSON object, the syntax is checked with encoding/json for its error
+// messages and the object is read as YAML (which JSON is a subset of) to find the line of each key
+func parseJson(contents []byte) ([]Variable, *ParseError) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(contents, &obj); err != nil {
+		switch e := err.(type) {
+		case *json.SyntaxError:
+			return nil, &ParseError{Line: lineAt(contents, e.Offset), Message: e.Error()}
+		case *json.UnmarshalTypeError:
+			return nil, &ParseError{Line: lineAt(contents, e.Offset), Message: "expected an object of keys and values"}
+		}
+
+		return nil, &ParseError{Message: err.Error()}
+	}
+
+	return parseYaml(contents)
+}
+
+var yamlLineRegex = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// parseYaml will parse a mapping of keys to strings, numbers or booleans
+func parseYaml(contents []byte) ([]Variable, *ParseError) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		if match := yamlLineRegex.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			return nil, &ParseError{Line: line, Message: match[2]}
+		}
+
+		return nil, &ParseError{Message: err.Error()}
+	}
+
+	// An empty file has no variables
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, &ParseError{Line: root.Line, Message: "expected a mapping of keys and values"}
+	}
+
+	vars := make([]Variable, 0, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, val := root.Content[i], root.Content[i+1]
+
+		switch {
+		case val.Kind != yaml.ScalarNode:
+			return nil, &ParseError{Line: val.Line, Message: fmt.Sprintf("the value of '%s' must be a string, number or boolean", key.Value)}
+		case val.Tag == "!!null":
+			vars = append(vars, Variable{Name: key.Value, Line: key.Line})
+		default:
+			vars = append(vars, Variable{Name: key.Value, Value: val.Value, Line: key.Line})
+		}
+	}
+
+	return vars, nil
+}
+
+// lineAt will find the line of the byte offset in the contents
+func lineAt(contents []byte, offset int64) int {
+	if offset > int64(len(contents)) {
+		offset = int64(len(contents))
+	}
+
+	return strings.Count(string(contents[:offset]), "\n") + 1
+}
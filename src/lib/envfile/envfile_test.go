@@ -0,0 +1,120 @@
+package envfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	// Helper for writing an env file into the test directory
+	writeFile := func(t *testing.T, name string, contents string) string {
+		fpath := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(fpath, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		return fpath
+	}
+
+	t.Run("should read a dotenv file with the line of each variable", func(t *testing.T) {
+		// Assemble
+		fpath := writeFile(t, "test.env", "# comment\nA=a\n\nexport B=\"b c\"\nA=again\n")
+
+		// Act
+		vars, err := Read(fpath, "")
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, []Variable{
+			{Name: "A", Value: "again", Line: 5},
+			{Name: "B", Value: "b c", Line: 4},
+		}, vars)
+	})
+
+	t.Run("should report the line of a dotenv file that can't be parsed", func(t *testing.T) {
+		// Assemble
+		fpath := writeFile(t, "test.env", "A=a\nnot a variable\n")
+
+		// Act
+		_, err := Read(fpath, "")
+
+		// Assert
+		assert.Equal(t, ParseError{File: fpath, Line: 2, Message: "Can't separate key from value"}, err)
+	})
+
+	t.Run("should read a JSON file", func(t *testing.T) {
+		// Assemble
+		fpath := writeFile(t, "test.json", "{\n  \"A\": \"a\",\n  \"N\": 1.50,\n  \"B\": true,\n  \"E\": null\n}")
+
+		// Act
+		vars, err := Read(fpath, "")
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, []Variable{
+			{Name: "A", Value: "a", Line: 2},
+			{Name: "N", Value: "1.50", Line: 3},
+			{Name: "B", Value: "true", Line: 4},
+			{Name: "E", Value: "", Line: 5},
+		}, vars)
+	})
+
+	t.Run("should report the line of a JSON syntax error", func(t *testing.T) {
+		// Assemble
+		fpath := writeFile(t, "test.json", "{\n  \"A\": \"a\",\n}")
+
+		// Act
+		_, err := Read(fpath, "")
+
+		// Assert
+		assert.ErrorContains(t, err, fpath+":3: invalid character")
+	})
+
+	t.Run("should read a YAML file with the format given", func(t *testing.T) {
+		// Assemble
+		fpath := writeFile(t, "test.txt", "A: a\nB: 'b: c'\n")
+
+		// Act
+		vars, err := Read(fpath, FORMAT_YAML)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, []Variable{{Name: "A", Value: "a", Line: 1}, {Name: "B", Value: "b: c", Line: 2}}, vars)
+	})
+
+	t.Run("should report values that aren't strings, numbers or booleans", func(t *testing.T) {
+		// Assemble
+		fpath := writeFile(t, "test.yaml", "A: a\nB:\n  - b\n")
+
+		// Act
+		_, err := Read(fpath, "")
+
+		// Assert
+		assert.Equal(t, ParseError{File: fpath, Line: 3, Message: "the value of 'B' must be a string, number or boolean"}, err)
+	})
+
+	t.Run("should report files that aren't a mapping", func(t *testing.T) {
+		// Assemble
+		fpath := writeFile(t, "test.yml", "- a\n- b\n")
+
+		// Act
+		_, err := Read(fpath, "")
+
+		// Assert
+		assert.ErrorContains(t, err, fpath+":1: expected a mapping")
+	})
+
+	t.Run("should report an unknown format", func(t *testing.T) {
+		// Assemble
+		fpath := writeFile(t, "test.env", "A=a\n")
+
+		// Act
+		_, err := Read(fpath, "toml")
+
+		// Assert
+		assert.ErrorContains(t, err, "unknown env file format 'toml'")
+	})
+}
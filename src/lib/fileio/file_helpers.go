@@ -2,6 +2,8 @@ package fileio
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 // FileExists will simply tell you if the file can be found at the given path
@@ -12,3 +14,17 @@ func FileExists(fpath string) bool {
 
 	return true
 }
+
+// ExpandHome will replace a leading ~ in the path with the current users home directory
+func ExpandHome(fpath string) string {
+	if fpath != "~" && !strings.HasPrefix(fpath, "~/") {
+		return fpath
+	}
+
+	dir, err := GetHomeDir()
+	if err != nil {
+		return fpath
+	}
+
+	return filepath.Join(dir, fpath[1:])
+}
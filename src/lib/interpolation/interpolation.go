@@ -0,0 +1,182 @@
+package interpolation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// References will return the names of all variables referenced in the string
+// in the order they first appear. Malformed references are ignored here and
+// reported by Expand
+func References(s string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 >= len(s) {
+			continue
+		}
+
+		switch s[i+1] {
+		case '$': // Escaped literal
+			i++
+		case '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return refs
+			}
+
+			name := s[i+2 : i+2+end]
+			if name != "" && !seen[name] {
+				seen[name] = true
+				refs = append(refs, name)
+			}
+
+			i += end + 2
+		}
+	}
+
+	return refs
+}
+
+// Expand will replace every ${NAME} reference in the string with the value returned by lookup
+// $$ is an escape for a literal $, any other lone $ is left as is
+func Expand(s string, lookup func(name string) (string, bool)) (string, error) {
+	var out strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 >= len(s) {
+			out.WriteByte(s[i])
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			out.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference in '%s'", s)
+			}
+
+			name := s[i+2 : i+2+end]
+			if name == "" {
+				return "", fmt.Errorf("empty variable reference in '%s'", s)
+			}
+
+			val, exists := lookup(name)
+			if !exists {
+				return "", fmt.Errorf("reference to undefined variable '%s'", name)
+			}
+
+			out.WriteString(val)
+			i += end + 2
+		default:
+			out.WriteByte(s[i])
+		}
+	}
+
+	return out.String(), nil
+}
+
+// ExpandKnown will replace the ${NAME} references that lookup knows, anything else (unknown or
+// malformed references) is left as is so strings with their own $ syntax, such as shell
+// commands, keep working. $$ is an escape, it is left as is and the reference after it isn't replaced
+func ExpandKnown(s string, lookup func(name string) (string, bool)) string {
+	var out strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '$' && i+1 < len(s) && s[i+1] == '$' {
+			out.WriteString("$$")
+			i++
+			continue
+		}
+
+		if s[i] == '$' && i+1 < len(s) && s[i+1] == '{' {
+			if end := strings.IndexByte(s[i+2:], '}'); end >= 0 {
+				if val, exists := lookup(s[i+2 : i+2+end]); exists {
+					out.WriteString(val)
+					i += end + 2
+					continue
+				}
+			}
+		}
+
+		out.WriteByte(s[i])
+	}
+
+	return out.String()
+}
+
+// Escape will escape every $ in the string so that Expand returns it unchanged
+func Escape(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+// Sort will order the keys so that every key comes after the keys it depends on
+// Keys that do not depend on each other keep their relative order from the input
+func Sort(keys []string, deps map[string][]string) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(keys))
+	ordered := make([]string, 0, len(keys))
+	var path []string
+
+	var visit func(key string) error
+	visit = func(key string) error {
+		switch state[key] {
+		case visited:
+			return nil
+		case visiting:
+			// Report the cycle starting from where it was entered
+			for i, p := range path {
+				if p == key {
+					return NewCycleError(append(append([]string{}, path[i:]...), key))
+				}
+			}
+		}
+
+		state[key] = visiting
+		path = append(path, key)
+
+		for _, dep := range deps[key] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[key] = visited
+		ordered = append(ordered, key)
+
+		return nil
+	}
+
+	for _, key := range keys {
+		if err := visit(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// CycleError is reported when variables reference each other in a loop
+type CycleError struct {
+	Vars []string
+}
+
+func (e CycleError) Error() string {
+	return fmt.Sprintf("circular variable reference found: %s", strings.Join(e.Vars, " -> "))
+}
+
+func NewCycleError(vars []string) error {
+	return CycleError{
+		Vars: vars,
+	}
+}
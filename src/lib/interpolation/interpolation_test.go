@@ -0,0 +1,114 @@
+package interpolation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferences(t *testing.T) {
+	t.Run("should return the referenced variables in order", func(t *testing.T) {
+		refs := References("postgres://${DB_USER}:${DB_PASS}@${DB_HOST}/${DB_USER}")
+
+		assert.Equal(t, []string{"DB_USER", "DB_PASS", "DB_HOST"}, refs)
+	})
+
+	t.Run("should ignore escaped and lone dollar signs", func(t *testing.T) {
+		refs := References("$$HOME costs $5 ${A}")
+
+		assert.Equal(t, []string{"A"}, refs)
+	})
+}
+
+func TestExpand(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		vals := map[string]string{"USER": "admin", "PASS": "hunter2"}
+		val, exists := vals[name]
+		return val, exists
+	}
+
+	t.Run("should replace the references", func(t *testing.T) {
+		val, err := Expand("${USER}:${PASS}@db", lookup)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "admin:hunter2@db", val)
+	})
+
+	t.Run("should unescape literal dollar signs", func(t *testing.T) {
+		val, err := Expand("$${USER} costs $5$$", lookup)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "${USER} costs $5$", val)
+	})
+
+	t.Run("should report an error for undefined variables", func(t *testing.T) {
+		_, err := Expand("${NOPE}", lookup)
+
+		assert.ErrorContains(t, err, "NOPE")
+	})
+
+	t.Run("should report an error for malformed references", func(t *testing.T) {
+		_, err := Expand("${USER", lookup)
+		assert.ErrorContains(t, err, "unterminated")
+
+		_, err = Expand("${}", lookup)
+		assert.ErrorContains(t, err, "empty")
+	})
+
+	t.Run("should round trip escaped values", func(t *testing.T) {
+		val, err := Expand(Escape("pa$$${word}"), lookup)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "pa$$${word}", val)
+	})
+}
+
+func TestExpandKnown(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		val, exists := map[string]string{"service": "billing"}[name]
+		return val, exists
+	}
+
+	t.Run("should only replace the known references", func(t *testing.T) {
+		val := ExpandKnown("kubectx ${service} && echo ${HOME} $$ $${service} ${unterminated", lookup)
+
+		assert.Equal(t, "kubectx billing && echo ${HOME} $$ $${service} ${unterminated", val)
+	})
+}
+
+func TestSort(t *testing.T) {
+	t.Run("should order keys after their dependencies", func(t *testing.T) {
+		keys := []string{"URL", "HOST", "PASS", "USER"}
+		deps := map[string][]string{
+			"URL":  {"USER", "PASS", "HOST"},
+			"PASS": {"USER"},
+		}
+
+		order, err := Sort(keys, deps)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"USER", "PASS", "HOST", "URL"}, order)
+	})
+
+	t.Run("should keep the input order for independent keys", func(t *testing.T) {
+		order, err := Sort([]string{"C", "A", "B"}, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"C", "A", "B"}, order)
+	})
+
+	t.Run("should report cycles with the variables involved", func(t *testing.T) {
+		deps := map[string][]string{
+			"A": {"B"},
+			"B": {"C"},
+			"C": {"A"},
+		}
+
+		_, err := Sort([]string{"A", "B", "C"}, deps)
+
+		var cycleErr CycleError
+		assert.ErrorAs(t, err, &cycleErr)
+		assert.Equal(t, []string{"A", "B", "C", "A"}, cycleErr.Vars)
+		assert.ErrorContains(t, err, "A -> B -> C -> A")
+	})
+}
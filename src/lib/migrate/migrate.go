@@ -0,0 +1,248 @@
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Migration rewrites a biome document from one version of the config format to the next
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(root *yaml.Node) []Change // Edits the document's root mapping in place
+}
+
+// Change describes a single edit made to a config file
+type Change struct {
+	Line    int // The line in the original file
+	Message string
+}
+
+// Result is the outcome of migrating the contents of a config file
+type Result struct {
+	Contents []byte
+	Changes  []Change
+}
+
+// Changed will determine if the migration changed anything
+func (r Result) Changed() bool {
+	return len(r.Changes) > 0
+}
+
+// Migrations holds every migration in order, one for each version of the config format
+var Migrations = []Migration{
+	{
+		From:        1,
+		Description: "add the version and encode list values",
+		Apply:       migrateV1,
+	},
+}
+
+// Migrate will rewrite every document in the contents to the current version of the config format
+//
+// The documents are edited as yaml nodes so comments and the order of keys are kept.
+// Documents that are already at the current version are left as they are
+func Migrate(contents []byte) (*Result, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+
+	var docs []*yaml.Node
+	var changes []Change
+
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return nil, err
+		}
+
+		docs = append(docs, &doc)
+
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+
+		docChanges, err := migrateDocument(doc.Content[0])
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, docChanges...)
+	}
+
+	if len(changes) == 0 {
+		return &Result{Contents: contents}, nil
+	}
+
+	var buff bytes.Buffer
+	encoder := yaml.NewEncoder(&buff)
+	encoder.SetIndent(detectIndent(contents))
+
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return &Result{Contents: buff.Bytes(), Changes: changes}, nil
+}
+
+// NeedsMigration will determine if any document in the contents is older than the current version
+func NeedsMigration(contents []byte) (bool, error) {
+	result, err := Migrate(contents)
+	if err != nil {
+		return false, err
+	}
+
+	return result.Changed(), nil
+}
+
+// migrateDocument will run every migration the document needs, oldest first
+func migrateDocument(root *yaml.Node) ([]Change, error) {
+	version, err := documentVersion(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if version > types.CONFIG_VERSION {
+		return nil, fmt.Errorf("line %d: config version %d is newer than this version of biome supports (up to version %d)", root.Line, version, types.CONFIG_VERSION)
+	}
+
+	if version == types.CONFIG_VERSION {
+		return nil, nil
+	}
+
+	changes := []Change{{Line: root.Line, Message: fmt.Sprintf("set the config version to %d", types.CONFIG_VERSION)}}
+	for _, migration := range Migrations {
+		if migration.From < version {
+			continue
+		}
+
+		changes = append(changes, migration.Apply(root)...)
+		version = migration.From + 1
+	}
+
+	setVersion(root, version)
+
+	return changes, nil
+}
+
+// documentVersion will return the config version of the document, documents without a version are version 1
+func documentVersion(root *yaml.Node) (int, error) {
+	node := mappingValue(root, "version")
+	if node == nil {
+		return 1, nil
+	}
+
+	var version int
+	if err := node.Decode(&version); err != nil {
+		return 0, fmt.Errorf("line %d: the config version must be a number", node.Line)
+	}
+
+	return version, nil
+}
+
+// migrateV1 will migrate a document from the format before versions were added
+func migrateV1(root *yaml.Node) []Change {
+	var changes []Change
+
+	// Lists used to be set as "[a b c]", they now need an explicit encoding
+	if env := mappingValue(root, "environment"); env != nil && env.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(env.Content); i += 2 {
+			key, val := env.Content[i], env.Content[i+1]
+			if val.Kind != yaml.SequenceNode {
+				continue
+			}
+
+			// The comment after the list stays on the variable's line
+			list := *val
+			if key.LineComment == "" {
+				key.LineComment = list.LineComment
+			}
+
+			list.LineComment = ""
+
+			*val = yaml.Node{
+				Kind: yaml.MappingNode,
+				Tag:  "!!map",
+				Content: []*yaml.Node{
+					scalarNode("value"), &list,
+					scalarNode("encode"), scalarNode("join"),
+					scalarNode("separator"), scalarNode(" "),
+				},
+				Line:   val.Line,
+				Column: val.Column,
+			}
+
+			changes = append(changes, Change{Line: val.Line, Message: fmt.Sprintf("encoded the list value of '%s' with join, it is no longer wrapped in []", key.Value)})
+		}
+	}
+
+	return changes
+}
+
+// setVersion will set the version of the document, adding it as the first key if needed
+func setVersion(root *yaml.Node, version int) {
+	if node := mappingValue(root, "version"); node != nil {
+		node.Kind, node.Tag, node.Value, node.Style = yaml.ScalarNode, "!!int", fmt.Sprint(version), 0
+		return
+	}
+
+	key := scalarNode("version")
+	val := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(version)}
+
+	// Keep the comment at the top of the document above the version
+	if len(root.Content) > 0 {
+		key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+
+	root.Content = append([]*yaml.Node{key, val}, root.Content...)
+}
+
+// detectIndent will find the indentation the file uses so the rewrite matches it
+func detectIndent(contents []byte) int {
+	indent := 0
+	for _, line := range strings.Split(string(contents), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		spaces := len(line) - len(trimmed)
+
+		if spaces == 0 || trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "- ") {
+			continue
+		}
+
+		if indent == 0 || spaces < indent {
+			indent = spaces
+		}
+	}
+
+	if indent < 2 {
+		return 2
+	}
+
+	return indent
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func scalarNode(val string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: val}
+}
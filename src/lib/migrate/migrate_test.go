@@ -0,0 +1,79 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Run("should migrate version 1 documents and keep the comments", func(t *testing.T) {
+		// Assemble
+		contents := `# Shared biomes
+name: base # the base
+environment:
+    # hosts to use
+    HOSTS: [a, b] # joined
+    PLAIN: x
+---
+name: child
+inherit_from: base # parent
+`
+
+		// Act
+		result, err := Migrate([]byte(contents))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, `# Shared biomes
+version: 2
+name: base # the base
+environment:
+    # hosts to use
+    HOSTS: # joined
+        value: [a, b]
+        encode: join
+        separator: ' '
+    PLAIN: x
+---
+version: 2
+name: child
+inherit_from: base # parent
+`, string(result.Contents))
+		assert.Equal(t, []Change{
+			{Line: 2, Message: "set the config version to 2"},
+			{Line: 5, Message: "encoded the list value of 'HOSTS' with join, it is no longer wrapped in []"},
+			{Line: 8, Message: "set the config version to 2"},
+		}, result.Changes)
+	})
+
+	t.Run("should leave current documents as they are", func(t *testing.T) {
+		// Assemble
+		contents := "---\nversion: 2\nname: current   # spacing is kept\n"
+
+		// Act
+		result, err := Migrate([]byte(contents))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.False(t, result.Changed())
+		assert.Equal(t, contents, string(result.Contents))
+	})
+
+	t.Run("should report documents that are newer than the binary", func(t *testing.T) {
+		// Act
+		_, err := Migrate([]byte("name: a\n---\nversion: 99\nname: b\n"))
+
+		// Assert
+		assert.ErrorContains(t, err, "line 3: config version 99 is newer than this version of biome supports")
+	})
+
+	t.Run("should report versions that aren't numbers", func(t *testing.T) {
+		// Act
+		needed, err := NeedsMigration([]byte("version: two\nname: a\n"))
+
+		// Assert
+		assert.False(t, needed)
+		assert.ErrorContains(t, err, "line 1: the config version must be a number")
+	})
+}
@@ -0,0 +1,229 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+)
+
+const SCHEMA_VERSION = "http://json-schema.org/draft-07/schema#"
+const SCHEMA_ID = "https://raw.githubusercontent.com/jeff-roche/biome/main/biome.schema.json"
+
+// Schema is a JSON Schema document
+type Schema map[string]interface{}
+
+// Generate will build the JSON Schema for a .biome.yaml document from the
+// yaml fields of types.BiomeConfig and the setter definitions
+//
+// Field descriptions and allowed values come from the desc and enum struct tags
+func Generate(setterDefinitions []types.SetterDefinition) Schema {
+	root := structSchema(reflect.TypeOf(types.BiomeConfig{}), setterDefinitions)
+
+	root["$schema"] = SCHEMA_VERSION
+	root["$id"] = SCHEMA_ID
+	root["title"] = "Biome configuration"
+	root["description"] = "A biome document in a .biome.yaml file"
+
+	// Documents that only include other files don't need a name
+	root["anyOf"] = []interface{}{
+		Schema{"required": []string{"name"}},
+		Schema{"required": []string{"include"}},
+	}
+
+	return root
+}
+
+// structSchema will build an object schema from the yaml fields of the struct type
+func structSchema(t reflect.Type, setterDefinitions []types.SetterDefinition) Schema {
+	properties := Schema{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		var prop Schema
+		switch {
+		case field.Type == reflect.TypeOf(map[string]interface{}{}):
+			prop = environmentSchema(setterDefinitions)
+		case field.Type == reflect.TypeOf(types.StringList{}):
+			prop = Schema{
+				"oneOf": []interface{}{
+					Schema{"type": "string"},
+					Schema{"type": "array", "items": Schema{"type": "string"}},
+				},
+			}
+		case field.Type == reflect.TypeOf(types.EnvFileList{}):
+			file := Schema{
+				"oneOf": []interface{}{
+					Schema{"type": "string"},
+					requireProperties(structSchema(reflect.TypeOf(types.EnvFile{}), setterDefinitions), "path"),
+				},
+			}
+
+			prop = Schema{
+				"oneOf": []interface{}{
+					Schema{"type": "string"},
+					Schema{"type": "array", "items": file},
+				},
+			}
+		case field.Type.Kind() == reflect.Struct:
+			prop = structSchema(field.Type, setterDefinitions)
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			prop = Schema{"type": "array", "items": structSchema(field.Type.Elem(), setterDefinitions)}
+		case field.Type.Kind() == reflect.Map && field.Type.Elem().Kind() == reflect.Struct:
+			// Entries can be left empty to use the defaults of every field
+			prop = Schema{
+				"type": "object",
+				"additionalProperties": Schema{
+					"oneOf": []interface{}{
+						structSchema(field.Type.Elem(), setterDefinitions),
+						Schema{"type": "null"},
+					},
+				},
+			}
+		default:
+			prop = typeSchema(field.Type)
+		}
+
+		if desc := field.Tag.Get("desc"); desc != "" {
+			prop["description"] = desc
+		}
+
+		if enum := field.Tag.Get("enum"); enum != "" {
+			prop["enum"] = strings.Split(enum, ",")
+		}
+
+		properties[name] = prop
+	}
+
+	return Schema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// requireProperties will mark the properties of an object schema as required
+func requireProperties(schema Schema, names ...string) Schema {
+	schema["required"] = names
+	return schema
+}
+
+// typeSchema will build the schema for a basic go type
+func typeSchema(t reflect.Type) Schema {
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	default:
+		return Schema{}
+	}
+}
+
+// environmentSchema will build the schema for the environment block, where each
+// value is either a basic value or one of the complex setters
+func environmentSchema(setterDefinitions []types.SetterDefinition) Schema {
+	values := []interface{}{
+		Schema{
+			"type":        []string{"string", "number", "boolean"},
+			"description": "The value to set",
+		},
+	}
+
+	for _, def := range setterDefinitions {
+		values = append(values, SetterSchema(def))
+	}
+
+	return Schema{
+		"type":                 "object",
+		"additionalProperties": Schema{"oneOf": values},
+	}
+}
+
+// SetterSchema will build the schema for a complex setter
+func SetterSchema(def types.SetterDefinition) Schema {
+	properties := Schema{def.Selector.Name: setterKeySchema(def.Selector)}
+	required := []string{def.Selector.Name}
+
+	for _, key := range def.Keys {
+		properties[key.Name] = setterKeySchema(key)
+
+		if key.Required {
+			required = append(required, key.Name)
+		}
+	}
+
+	schema := Schema{
+		"type":                 "object",
+		"description":          def.Description,
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+
+	// A boolean key only counts when it is true
+	if len(def.ExactlyOne) > 0 {
+		branches := make([]interface{}, 0, len(def.ExactlyOne))
+		for _, name := range def.ExactlyOne {
+			branch := Schema{"required": []string{name}}
+			if key, ok := properties[name].(Schema); ok && key["type"] == "boolean" {
+				branch["properties"] = Schema{name: Schema{"const": true}}
+			}
+
+			branches = append(branches, branch)
+		}
+
+		schema["oneOf"] = branches
+	}
+
+	return schema
+}
+
+func setterKeySchema(key types.SetterKey) Schema {
+	var prop Schema
+	switch key.Type {
+	case types.SETTER_VALUE_BOOL:
+		prop = Schema{"type": "boolean"}
+	case types.SETTER_VALUE_SCALAR:
+		prop = Schema{"type": []string{"string", "number", "boolean"}}
+	case types.SETTER_VALUE_ANY:
+		prop = Schema{"type": []string{"string", "number", "boolean", "array", "object"}}
+	default:
+		prop = Schema{"type": "string"}
+	}
+
+	if key.Description != "" {
+		prop["description"] = key.Description
+	}
+
+	if len(key.Enum) > 0 {
+		prop["enum"] = key.Enum
+	}
+
+	if key.Const != nil {
+		prop["const"] = key.Const
+	}
+
+	return prop
+}
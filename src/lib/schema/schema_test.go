@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/jeff-roche/biome/src/lib/setters"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	generated := Generate(setters.SetterDefinitions)
+
+	// Helper for walking down the schema
+	getSchema := func(s Schema, path ...string) Schema {
+		for _, p := range path {
+			s = s[p].(Schema)
+		}
+
+		return s
+	}
+
+	t.Run("should include every config field", func(t *testing.T) {
+		properties := getSchema(generated, "properties")
+
+		for _, field := range []string{"name", "aws_profile", "commands", "load_env", "environment", "inherit_from", "merge", "include"} {
+			assert.Contains(t, properties, field)
+		}
+
+		assert.NotContains(t, properties, "envorder")
+		assert.NotContains(t, properties, "sourcefile")
+	})
+
+	t.Run("should include the descriptions and enums from the struct tags", func(t *testing.T) {
+		commands := getSchema(generated, "properties", "merge", "properties", "commands")
+
+		assert.Equal(t, []string{"prepend", "append", "replace"}, commands["enum"])
+		assert.NotEmpty(t, commands["description"])
+	})
+
+	t.Run("should include every setter key", func(t *testing.T) {
+		values := getSchema(generated, "properties", "environment", "additionalProperties")["oneOf"].([]interface{})
+
+		assert.Len(t, values, len(setters.SetterDefinitions)+1)
+
+		for i, def := range setters.SetterDefinitions {
+			setter := values[i+1].(Schema)
+			properties := setter["properties"].(Schema)
+
+			assert.Contains(t, properties, def.Selector.Name)
+			assert.Contains(t, setter["required"], def.Selector.Name)
+
+			for _, key := range def.Keys {
+				assert.Contains(t, properties, key.Name)
+
+				if key.Required {
+					assert.Contains(t, setter["required"], key.Name)
+				}
+			}
+		}
+	})
+
+	t.Run("should include the setter constraints", func(t *testing.T) {
+		// Assemble
+		values := getSchema(generated, "properties", "environment", "additionalProperties")["oneOf"].([]interface{})
+		setterSchemas := make(map[string]Schema)
+		for i, def := range setters.SetterDefinitions {
+			setterSchemas[def.Selector.Name] = values[i+1].(Schema)
+		}
+
+		// Assert
+		assert.Equal(t, []interface{}{
+			Schema{"required": []string{"secret_json_key"}},
+			Schema{"required": []string{"secret_whole"}, "properties": Schema{"secret_whole": Schema{"const": true}}},
+		}, setterSchemas["secret_arn"]["oneOf"])
+		assert.Equal(t, true, getSchema(setterSchemas["from_cli"], "properties", "from_cli")["const"])
+	})
+
+	t.Run("should match the published schema", func(t *testing.T) {
+		published, err := os.ReadFile("../../../biome.schema.json")
+		assert.Nil(t, err)
+
+		out, err := json.MarshalIndent(generated, "", "  ")
+		assert.Nil(t, err)
+
+		assert.JSONEq(t, string(published), string(out), "biome.schema.json is out of date, regenerate it with 'go run . schema > biome.schema.json'")
+	})
+}
@@ -3,8 +3,15 @@ package setters
 import (
 	"fmt"
 	"os"
+
+	"github.com/jeff-roche/biome/src/lib/interpolation"
 )
 
+const VALUE_ENV_KEY = "value"
+
+// Lookup is used to find the value of the variables referenced with ${NAME}
+type Lookup func(name string) (string, bool)
+
 type BasicEnvironmentSetter struct {
 	Key   string
 	Value string
@@ -20,3 +27,28 @@ func NewBasicEnvironmentSetter(key string, value interface{}) *BasicEnvironmentS
 func (s BasicEnvironmentSetter) SetEnv() (string, error) {
 	return s.Value, os.Setenv(s.Key, s.Value)
 }
+
+// InterpolatedEnvironmentSetter will expand the ${NAME} references in the value when it is set,
+// so the variables it references only need to be set by then
+type InterpolatedEnvironmentSetter struct {
+	Key    string
+	Value  string
+	lookup Lookup
+}
+
+func NewInterpolatedEnvironmentSetter(key string, value string, lookup Lookup) *InterpolatedEnvironmentSetter {
+	return &InterpolatedEnvironmentSetter{
+		Key:    key,
+		Value:  value,
+		lookup: lookup,
+	}
+}
+
+func (s InterpolatedEnvironmentSetter) SetEnv() (string, error) {
+	val, err := interpolation.Expand(s.Value, s.lookup)
+	if err != nil {
+		return "", err
+	}
+
+	return val, os.Setenv(s.Key, val)
+}
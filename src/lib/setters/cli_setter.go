@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/jeff-roche/biome/src/lib/types"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
@@ -21,16 +22,7 @@ type CLIEnvironmentSetter struct {
 
 func NewCLIEnvironmentSetter(key string, rd io.Reader, isSecret bool) (*CLIEnvironmentSetter, error) {
 	// Get the value from the io.Reader
-	fmt.Printf("%s: ", key)
-	var val string
-	var err error
-
-	if isSecret {
-		val, err = getSecretCliInput(rd)
-	} else {
-		val, err = getCliInput(rd)
-	}
-
+	val, err := promptCliValue(key, rd, isSecret)
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +38,73 @@ func (s CLIEnvironmentSetter) SetEnv() (string, error) {
 	return s.Value, os.Setenv(s.Key, s.Value)
 }
 
+// CliInput asks for the values of the CLI setters, Prompt asks up front so the prompts come
+// before any setter reaches out to the network and the setters built later use the entered values
+type CliInput struct {
+	rd      io.Reader
+	entered map[string][]string // The values entered for each variable, in the order its setters are built
+}
+
+func NewCliInput(rd io.Reader) *CliInput {
+	return &CliInput{
+		rd:      rd,
+		entered: make(map[string][]string),
+	}
+}
+
+// Prompt will ask for the value of every CLI setter in the environment config, including inherited ones
+func (c *CliInput) Prompt(key string, node interface{}) error {
+	switch val := node.(type) {
+	case types.LayeredEnv:
+		if err := c.Prompt(key, val.Base); err != nil {
+			return err
+		}
+
+		return c.Prompt(key, val.Config)
+	case map[string]interface{}:
+		if fromCli, _ := val[CLI_ENVIRONMENT_SETTER_KEY].(bool); !fromCli {
+			return nil
+		}
+
+		// The current value is kept without prompting
+		if _, exists := os.LookupEnv(key); exists && types.EnvMode(val) == types.ENV_MODE_DEFAULT {
+			return nil
+		}
+
+		isSecret, _ := val[CLI_ENVIRONMENT_SECRET_SETTER_KEY].(bool)
+
+		entered, err := promptCliValue(key, c.rd, isSecret)
+		if err != nil {
+			return err
+		}
+
+		c.entered[key] = append(c.entered[key], entered)
+	}
+
+	return nil
+}
+
+// newSetter will build the setter from the next value entered for the variable, prompting if there isn't one
+func (c *CliInput) newSetter(key string, isSecret bool) (*CLIEnvironmentSetter, error) {
+	if entered := c.entered[key]; len(entered) > 0 {
+		c.entered[key] = entered[1:]
+		return &CLIEnvironmentSetter{Key: key, Value: entered[0]}, nil
+	}
+
+	return NewCLIEnvironmentSetter(key, c.rd, isSecret)
+}
+
+// promptCliValue will ask for the value of the variable on the command line
+func promptCliValue(key string, rd io.Reader, isSecret bool) (string, error) {
+	fmt.Printf("%s: ", key)
+
+	if isSecret {
+		return getSecretCliInput(rd)
+	}
+
+	return getCliInput(rd)
+}
+
 func getCliInput(rd io.Reader) (string, error) {
 	reader := bufio.NewReader(rd)
 	val, err := reader.ReadString('\n')
@@ -0,0 +1,178 @@
+package setters
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jeff-roche/biome/src/lib/cmdr"
+	"github.com/jeff-roche/biome/src/lib/interpolation"
+)
+
+const COMMAND_ENV_KEY = "from_command"
+const COMMAND_SHELL_ENV_KEY = "shell"
+const COMMAND_TIMEOUT_ENV_KEY = "timeout"
+const COMMAND_TRIM_ENV_KEY = "trim"
+const COMMAND_SECRET_ENV_KEY = "secret"
+
+// CommandEnvironmentSetter will set an environment variable to the output of a command,
+// such as git rev-parse HEAD or gcloud auth print-access-token
+//
+// Any ${NAME} references in the arguments are expanded before the command is run. A shell string is
+// passed to the shell as is, so values are never parsed as shell syntax, and the variables it references
+// are exported to the command for the shell to expand.
+// Trailing newlines are removed from the output the same as a shell's $(...)
+type CommandEnvironmentSetter struct {
+	EnvKey  string
+	Args    []string      // The command and its arguments, or a single shell string when Shell is set
+	Shell   bool          // Run the command with the shell (sh -c, or cmd /C on Windows)
+	Timeout time.Duration // How long the command can run for, 0 waits forever
+	Trim    bool          // Remove all of the whitespace around the output
+	Secret  bool          // The output is a secret, biome show redacts it
+	lookup  Lookup
+	run     func(timeout time.Duration, env []string, cmdStr string, args ...string) (string, error)
+}
+
+// NewCommandEnvironmentSetter is the builder function for CommandEnvironmentSetter
+func NewCommandEnvironmentSetter(key string, node map[string]interface{}, lookup Lookup) (*CommandEnvironmentSetter, error) {
+	setter := &CommandEnvironmentSetter{
+		EnvKey: key,
+		lookup: lookup,
+		run:    cmdr.Output,
+	}
+
+	flags := map[string]*bool{
+		COMMAND_SHELL_ENV_KEY:  &setter.Shell,
+		COMMAND_TRIM_ENV_KEY:   &setter.Trim,
+		COMMAND_SECRET_ENV_KEY: &setter.Secret,
+	}
+
+	for name, flag := range flags {
+		if val, exists := node[name]; exists {
+			var ok bool
+			if *flag, ok = val.(bool); !ok {
+				return nil, newSetterTypeError(key, name, "true or false")
+			}
+		}
+	}
+
+	switch command := node[COMMAND_ENV_KEY].(type) {
+	case string:
+		// A string is only split up by a shell, so it has to be marked as one
+		if !setter.Shell {
+			return nil, fmt.Errorf("'%s' for variable '%s' is a string, use a list of arguments or set '%s: true' to run it with the shell",
+				COMMAND_ENV_KEY, key, COMMAND_SHELL_ENV_KEY)
+		}
+
+		setter.Args = []string{command}
+	case []interface{}:
+		if setter.Shell {
+			return nil, fmt.Errorf("'%s' for variable '%s' must be a string to run it with the shell", COMMAND_ENV_KEY, key)
+		}
+
+		for _, arg := range command {
+			switch arg.(type) {
+			case string, int, float64, bool:
+				setter.Args = append(setter.Args, fmt.Sprint(arg))
+			default:
+				return nil, newSetterTypeError(key, COMMAND_ENV_KEY, "a list of arguments")
+			}
+		}
+	}
+
+	if len(setter.Args) == 0 || setter.Args[0] == "" {
+		return nil, newSetterTypeError(key, COMMAND_ENV_KEY, "a list of arguments or a shell string")
+	}
+
+	if val, exists := node[COMMAND_TIMEOUT_ENV_KEY]; exists {
+		timeout, err := parseTimeout(val)
+		if err != nil {
+			return nil, newSetterTypeError(key, COMMAND_TIMEOUT_ENV_KEY, "a duration (ex: 30s) or a number of seconds")
+		}
+
+		setter.Timeout = timeout
+	}
+
+	return setter, nil
+}
+
+func (s CommandEnvironmentSetter) SetEnv() (string, error) {
+	var args, env []string
+
+	if s.Shell {
+		env = s.shellEnv(s.Args[0])
+
+		if runtime.GOOS == "windows" {
+			args = []string{"cmd", "/C", s.Args[0]}
+		} else {
+			args = []string{"sh", "-c", s.Args[0]}
+		}
+	} else {
+		for _, arg := range s.Args {
+			if s.lookup != nil {
+				var err error
+				if arg, err = interpolation.Expand(arg, s.lookup); err != nil {
+					return "", err
+				}
+			}
+
+			args = append(args, arg)
+		}
+	}
+
+	out, err := s.run(s.Timeout, env, args[0], args[1:]...)
+	if err != nil {
+		return "", fmt.Errorf("the command '%s' failed: %v", strings.Join(s.Args, " "), err)
+	}
+
+	val := strings.TrimRight(out, "\r\n")
+	if s.Trim {
+		val = strings.TrimSpace(val)
+	}
+
+	return val, os.Setenv(s.EnvKey, val)
+}
+
+// shellEnv will export the variables the shell string references (params aren't in the environment
+// under their own name), undefined variables are left for the shell to expand
+func (s CommandEnvironmentSetter) shellEnv(cmd string) []string {
+	if s.lookup == nil {
+		return nil
+	}
+
+	var env []string
+	for _, name := range interpolation.References(cmd) {
+		if !shellName.MatchString(name) {
+			continue
+		}
+
+		if val, exists := s.lookup(name); exists {
+			env = append(env, name+"="+val)
+		}
+	}
+
+	return env
+}
+
+// shellName matches the names a shell can expand with ${NAME}
+var shellName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseTimeout will parse a duration (30s, 2m) or a number of seconds
+func parseTimeout(val interface{}) (time.Duration, error) {
+	switch timeout := val.(type) {
+	case int:
+		if timeout > 0 {
+			return time.Duration(timeout) * time.Second, nil
+		}
+	case string:
+		duration, err := time.ParseDuration(timeout)
+		if err == nil && duration > 0 {
+			return duration, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid timeout %v", val)
+}
@@ -0,0 +1,135 @@
+package setters
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandSetter(t *testing.T) {
+	testEnv := "BIOME_TEST_COMMAND_ENV"
+
+	// Helper for building the setter with the command runner replaced
+	getTestSetter := func(t *testing.T, node map[string]interface{}, lookup Lookup, output string) (*CommandEnvironmentSetter, *[]string) {
+		setter, err := NewCommandEnvironmentSetter(testEnv, node, lookup)
+		assert.Nil(t, err)
+
+		var ran []string
+		setter.run = func(timeout time.Duration, env []string, cmdStr string, args ...string) (string, error) {
+			ran = append([]string{cmdStr}, args...)
+			return output, nil
+		}
+
+		return setter, &ran
+	}
+
+	t.Cleanup(func() {
+		os.Unsetenv(testEnv)
+	})
+
+	t.Run("should set the output of the command without its trailing newline", func(t *testing.T) {
+		// Assemble
+		lookup := func(name string) (string, bool) { return "main", name == "BRANCH" }
+		setter, ran := getTestSetter(t, map[string]interface{}{"from_command": []interface{}{"git", "rev-parse", "${BRANCH}"}}, lookup, "  abc123\n\n")
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"git", "rev-parse", "main"}, *ran)
+		assert.Equal(t, "  abc123", val)
+		assert.Equal(t, "  abc123", os.Getenv(testEnv))
+	})
+
+	t.Run("should trim the output", func(t *testing.T) {
+		// Assemble
+		setter, _ := getTestSetter(t, map[string]interface{}{"from_command": []interface{}{"print-token"}, "trim": true}, nil, "  token \n")
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "token", val)
+	})
+
+	t.Run("should run a string with the shell", func(t *testing.T) {
+		// Assemble
+		setter, ran := getTestSetter(t, map[string]interface{}{"from_command": "git log -1 | cut -c1-7", "shell": true}, nil, "abc\n")
+
+		// Act
+		_, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "git log -1 | cut -c1-7", (*ran)[len(*ran)-1])
+	})
+
+	t.Run("should let the shell expand references instead of pasting them into the command", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("the test commands need sh")
+		}
+
+		// Assemble
+		lookup := func(name string) (string, bool) {
+			return "x; echo injected", name == "STAGE"
+		}
+		setter, err := NewCommandEnvironmentSetter(testEnv, map[string]interface{}{"from_command": `printf '%s' "${STAGE}"`, "shell": true}, lookup)
+		assert.Nil(t, err)
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "x; echo injected", val)
+	})
+
+	t.Run("should read the timeout as a duration or seconds", func(t *testing.T) {
+		// Act
+		duration, durationErr := NewCommandEnvironmentSetter(testEnv, map[string]interface{}{"from_command": []interface{}{"a"}, "timeout": "1m"}, nil)
+		seconds, secondsErr := NewCommandEnvironmentSetter(testEnv, map[string]interface{}{"from_command": []interface{}{"a"}, "timeout": 5}, nil)
+		_, invalidErr := NewCommandEnvironmentSetter(testEnv, map[string]interface{}{"from_command": []interface{}{"a"}, "timeout": "soon"}, nil)
+
+		// Assert
+		assert.Nil(t, durationErr)
+		assert.Equal(t, time.Minute, duration.Timeout)
+		assert.Nil(t, secondsErr)
+		assert.Equal(t, 5*time.Second, seconds.Timeout)
+		assert.EqualError(t, invalidErr, "'timeout' for variable 'BIOME_TEST_COMMAND_ENV' must be a duration (ex: 30s) or a number of seconds")
+	})
+
+	t.Run("should require a string to be marked as shell", func(t *testing.T) {
+		// Act
+		_, err := NewCommandEnvironmentSetter(testEnv, map[string]interface{}{"from_command": "git rev-parse HEAD"}, nil)
+
+		// Assert
+		assert.ErrorContains(t, err, "is a string, use a list of arguments or set 'shell: true' to run it with the shell")
+	})
+
+	t.Run("should run the command and report failures and timeouts", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("the test commands need sh")
+		}
+
+		// Assemble
+		echo, _ := NewCommandEnvironmentSetter(testEnv, map[string]interface{}{"from_command": "printf 'a b\\n'", "shell": true}, nil)
+		fail, _ := NewCommandEnvironmentSetter(testEnv, map[string]interface{}{"from_command": []interface{}{"sh", "-c", "exit 3"}}, nil)
+		slow, _ := NewCommandEnvironmentSetter(testEnv, map[string]interface{}{"from_command": []interface{}{"sleep", "5"}, "timeout": "50ms"}, nil)
+
+		// Act
+		val, echoErr := echo.SetEnv()
+		_, failErr := fail.SetEnv()
+		_, slowErr := slow.SetEnv()
+
+		// Assert
+		assert.Nil(t, echoErr)
+		assert.Equal(t, "a b", val)
+		assert.EqualError(t, failErr, "the command 'sh -c exit 3' failed: exit status 3")
+		assert.EqualError(t, slowErr, "the command 'sleep 5' failed: timed out after 50ms")
+	})
+}
@@ -0,0 +1,213 @@
+package setters
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jeff-roche/biome/src/lib/interpolation"
+	"github.com/jeff-roche/biome/src/lib/types"
+	"gopkg.in/yaml.v3"
+)
+
+const ENCODE_ENV_KEY = "encode"
+
+const (
+	ENCODE_JSON = "json" // Lists and maps
+	ENCODE_YAML = "yaml" // Maps
+	ENCODE_CSV  = "csv"  // Lists, as a single CSV record
+	ENCODE_JOIN = "join" // Lists, joined with the separator
+)
+
+var Encodings = []string{ENCODE_JSON, ENCODE_YAML, ENCODE_CSV, ENCODE_JOIN}
+
+const DEFAULT_ENCODE_SEPARATOR = ","
+
+// EncodedEnvironmentSetter will encode a list or map value into a string when it is set
+//
+// Any ${NAME} references in the strings of the value are expanded before it is encoded
+type EncodedEnvironmentSetter struct {
+	Key       string
+	Value     interface{}
+	Encoding  string
+	Separator string
+	lookup    Lookup
+}
+
+func NewEncodedEnvironmentSetter(key string, node map[string]interface{}, lookup Lookup) (*EncodedEnvironmentSetter, error) {
+	encoding, ok := node[ENCODE_ENV_KEY].(string)
+	if !ok || !types.Contains(Encodings, encoding) {
+		return nil, newSetterTypeError(key, ENCODE_ENV_KEY, fmt.Sprintf("one of %s", strings.Join(Encodings, ", ")))
+	}
+
+	separator := DEFAULT_ENCODE_SEPARATOR
+	if val, exists := node[SEPARATOR_ENV_KEY]; exists {
+		if separator, ok = val.(string); !ok {
+			return nil, newSetterTypeError(key, SEPARATOR_ENV_KEY, "a string")
+		}
+	}
+
+	value := node[VALUE_ENV_KEY]
+
+	switch value.(type) {
+	case []interface{}:
+		if encoding == ENCODE_YAML {
+			return nil, fmt.Errorf("'%s' for variable '%s' is a list, which can be encoded with %s, %s or %s", VALUE_ENV_KEY, key, ENCODE_JSON, ENCODE_CSV, ENCODE_JOIN)
+		}
+	case map[string]interface{}:
+		if encoding == ENCODE_CSV || encoding == ENCODE_JOIN {
+			return nil, fmt.Errorf("'%s' for variable '%s' is a map, which can be encoded with %s or %s", VALUE_ENV_KEY, key, ENCODE_JSON, ENCODE_YAML)
+		}
+	default:
+		return nil, fmt.Errorf("'%s' for variable '%s' must be a list or a map to use '%s'", VALUE_ENV_KEY, key, ENCODE_ENV_KEY)
+	}
+
+	return &EncodedEnvironmentSetter{
+		Key:       key,
+		Value:     value,
+		Encoding:  encoding,
+		Separator: separator,
+		lookup:    lookup,
+	}, nil
+}
+
+func (s EncodedEnvironmentSetter) SetEnv() (string, error) {
+	value, err := expandValue(s.Value, s.lookup)
+	if err != nil {
+		return "", err
+	}
+
+	val, err := encodeValue(value, s.Encoding, s.Separator)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode the value as %s: %v", s.Encoding, err)
+	}
+
+	return val, os.Setenv(s.Key, val)
+}
+
+// encodeValue will encode the list or map into a string
+func encodeValue(value interface{}, encoding string, separator string) (string, error) {
+	switch encoding {
+	case ENCODE_JSON:
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	case ENCODE_YAML:
+		encoded, err := yaml.Marshal(value)
+		return strings.TrimSuffix(string(encoded), "\n"), err
+	}
+
+	items, err := listItems(value.([]interface{}))
+	if err != nil {
+		return "", err
+	}
+
+	if encoding == ENCODE_JOIN {
+		return strings.Join(items, separator), nil
+	}
+
+	// CSV
+	var buff bytes.Buffer
+	writer := csv.NewWriter(&buff)
+	if separator != DEFAULT_ENCODE_SEPARATOR {
+		runes := []rune(separator)
+		if len(runes) != 1 {
+			return "", fmt.Errorf("the csv separator must be a single character")
+		}
+
+		writer.Comma = runes[0]
+	}
+
+	if err := writer.Write(items); err != nil {
+		return "", err
+	}
+
+	writer.Flush()
+
+	return strings.TrimSuffix(buff.String(), "\n"), writer.Error()
+}
+
+// listItems will convert the items of a list to strings, nested lists and maps can't be converted
+func listItems(list []interface{}) ([]string, error) {
+	items := make([]string, 0, len(list))
+	for i, item := range list {
+		switch item.(type) {
+		case []interface{}, map[string]interface{}:
+			return nil, fmt.Errorf("item %d is not a string, number or boolean", i)
+		case nil:
+			items = append(items, "")
+		default:
+			items = append(items, fmt.Sprint(item))
+		}
+	}
+
+	return items, nil
+}
+
+// expandValue will expand the ${NAME} references in every string of a list or map
+func expandValue(value interface{}, lookup Lookup) (interface{}, error) {
+	if lookup == nil {
+		return value, nil
+	}
+
+	switch val := value.(type) {
+	case string:
+		return interpolation.Expand(val, lookup)
+	case []interface{}:
+		expanded := make([]interface{}, len(val))
+		for i, item := range val {
+			var err error
+			if expanded[i], err = expandValue(item, lookup); err != nil {
+				return nil, err
+			}
+		}
+
+		return expanded, nil
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(val))
+		for key, item := range val {
+			var err error
+			if expanded[key], err = expandValue(item, lookup); err != nil {
+				return nil, err
+			}
+		}
+
+		return expanded, nil
+	}
+
+	return value, nil
+}
+
+// valueReferences will return the names of the variables referenced in every string of a value
+func valueReferences(value interface{}) []string {
+	switch val := value.(type) {
+	case string:
+		return interpolation.References(val)
+	case []interface{}:
+		var refs []string
+		for _, item := range val {
+			refs = append(refs, valueReferences(item)...)
+		}
+
+		return refs
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		var refs []string
+		for _, key := range keys {
+			refs = append(refs, valueReferences(val[key])...)
+		}
+
+		return refs
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package setters
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodedSetter(t *testing.T) {
+	testEnvKey := "FOOBAR_TEST_KEY"
+	lookup := func(name string) (string, bool) { return "db.internal", name == "DB_HOST" }
+
+	t.Cleanup(func() {
+		os.Unsetenv(testEnvKey)
+	})
+
+	for _, tc := range []struct {
+		name string
+		node map[string]interface{}
+		want string
+	}{
+		{
+			"should join a list with the default separator",
+			map[string]interface{}{"value": []interface{}{"a", 1, true}, "encode": "join"},
+			"a,1,true",
+		},
+		{
+			"should join a list with a custom separator",
+			map[string]interface{}{"value": []interface{}{"a", "b"}, "encode": "join", "separator": " "},
+			"a b",
+		},
+		{
+			"should quote csv items when needed",
+			map[string]interface{}{"value": []interface{}{"plain", "with,comma", `with "quotes"`}, "encode": "csv"},
+			`plain,"with,comma","with ""quotes"""`,
+		},
+		{
+			"should encode a list as json",
+			map[string]interface{}{"value": []interface{}{"a", 2}, "encode": "json"},
+			`["a",2]`,
+		},
+		{
+			"should encode a map as json with expanded references",
+			map[string]interface{}{"value": map[string]interface{}{"host": "${DB_HOST}", "ports": []interface{}{5432}}, "encode": "json"},
+			`{"host":"db.internal","ports":[5432]}`,
+		},
+		{
+			"should encode a map as yaml",
+			map[string]interface{}{"value": map[string]interface{}{"level": "debug", "json": true}, "encode": "yaml"},
+			"json: true\nlevel: debug",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Assemble
+			setter, err := GetEnvironmentSetter(testEnvKey, tc.node, SetterOptions{Lookup: lookup})
+			assert.Nil(t, err)
+
+			// Act
+			val, err := setter.SetEnv()
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, val)
+			assert.Equal(t, tc.want, os.Getenv(testEnvKey))
+		})
+	}
+
+	t.Run("should report values that can't be encoded", func(t *testing.T) {
+		for _, tc := range []struct {
+			node map[string]interface{}
+			want string
+		}{
+			{map[string]interface{}{"value": []interface{}{"a"}}, "set 'encode' to one of json, yaml, csv, join"},
+			{map[string]interface{}{"value": []interface{}{"a"}, "encode": "xml"}, "'encode' for variable 'FOOBAR_TEST_KEY' must be one of json, yaml, csv, join"},
+			{map[string]interface{}{"value": []interface{}{"a"}, "encode": "yaml"}, "is a list, which can be encoded with json, csv or join"},
+			{map[string]interface{}{"value": map[string]interface{}{"a": "b"}, "encode": "csv"}, "is a map, which can be encoded with json or yaml"},
+			{map[string]interface{}{"value": "scalar", "encode": "json"}, "must be a list or a map to use 'encode'"},
+			{map[string]interface{}{"value": []interface{}{"a"}, "encode": "join", "separator": false}, "'separator' for variable 'FOOBAR_TEST_KEY' must be a string"},
+		} {
+			_, err := GetEnvironmentSetter(testEnvKey, tc.node, SetterOptions{Lookup: lookup})
+			assert.ErrorContains(t, err, tc.want)
+		}
+	})
+
+	t.Run("should report nested lists that can't be joined", func(t *testing.T) {
+		// Assemble
+		setter, _ := GetEnvironmentSetter(testEnvKey, map[string]interface{}{"value": []interface{}{[]interface{}{"a"}}, "encode": "join"}, SetterOptions{Lookup: lookup})
+
+		// Act
+		_, err := setter.SetEnv()
+
+		// Assert
+		assert.ErrorContains(t, err, "unable to encode the value as join: item 0 is not a string, number or boolean")
+	})
+
+	t.Run("should report the references in the value", func(t *testing.T) {
+		node := map[string]interface{}{"value": map[string]interface{}{"b": "${B}", "a": []interface{}{"${A}"}}, "encode": "json"}
+
+		assert.Equal(t, []string{"A", "B"}, GetReferences(node))
+	})
+
+	t.Run("should report plain lists", func(t *testing.T) {
+		_, err := GetEnvironmentSetter(testEnvKey, []interface{}{"a", "b"}, SetterOptions{Lookup: lookup})
+
+		assert.ErrorContains(t, err, "variable 'FOOBAR_TEST_KEY' is a list, use 'value' with 'encode' to encode it")
+	})
+}
@@ -0,0 +1,197 @@
+package setters
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jeff-roche/biome/src/lib/fileio"
+	"github.com/jeff-roche/biome/src/lib/interpolation"
+)
+
+const FILE_ENV_KEY = "from_file"
+const FILE_TRIM_ENV_KEY = "trim"
+const FILE_SINGLE_LINE_ENV_KEY = "single_line"
+const FILE_BASE64_ENV_KEY = "base64"
+const FILE_MAX_SIZE_ENV_KEY = "max_size"
+const FILE_ALLOW_WORLD_READABLE_ENV_KEY = "allow_world_readable"
+
+// DEFAULT_MAX_FILE_SIZE is the largest file that is read without raising max_size (1 MiB)
+const DEFAULT_MAX_FILE_SIZE = 1024 * 1024
+
+// FileEnvironmentSetter will set an environment variable to the contents of a file,
+// such as a certificate, a private key or a Docker secret in /run/secrets
+//
+// Any ${NAME} references in the path are expanded when it is set, then a leading ~ is the
+// home directory and a relative path is relative to the directory of the config file
+type FileEnvironmentSetter struct {
+	EnvKey             string
+	Path               string
+	Dir                string // The directory relative paths are resolved from
+	Trim               bool   // Remove the whitespace around the contents
+	SingleLine         bool   // Only use the first line of the file
+	Base64             bool   // Encode the contents with base64, for binary files
+	MaxSize            int64  // The largest file in bytes that will be read
+	AllowWorldReadable bool   // Read a private key even if everyone can read it
+	lookup             Lookup
+}
+
+// NewFileEnvironmentSetter is the builder function for FileEnvironmentSetter
+func NewFileEnvironmentSetter(key string, node map[string]interface{}, lookup Lookup, dir string) (*FileEnvironmentSetter, error) {
+	path, ok := node[FILE_ENV_KEY].(string)
+	if !ok || path == "" {
+		return nil, newSetterTypeError(key, FILE_ENV_KEY, "a path")
+	}
+
+	setter := &FileEnvironmentSetter{
+		EnvKey:  key,
+		Path:    path,
+		Dir:     dir,
+		MaxSize: DEFAULT_MAX_FILE_SIZE,
+		lookup:  lookup,
+	}
+
+	flags := map[string]*bool{
+		FILE_TRIM_ENV_KEY:                 &setter.Trim,
+		FILE_SINGLE_LINE_ENV_KEY:          &setter.SingleLine,
+		FILE_BASE64_ENV_KEY:               &setter.Base64,
+		FILE_ALLOW_WORLD_READABLE_ENV_KEY: &setter.AllowWorldReadable,
+	}
+
+	for name, flag := range flags {
+		if val, exists := node[name]; exists {
+			if *flag, ok = val.(bool); !ok {
+				return nil, newSetterTypeError(key, name, "true or false")
+			}
+		}
+	}
+
+	if val, exists := node[FILE_MAX_SIZE_ENV_KEY]; exists {
+		size, ok := val.(int)
+		if !ok || size <= 0 {
+			return nil, newSetterTypeError(key, FILE_MAX_SIZE_ENV_KEY, "a number of bytes")
+		}
+
+		setter.MaxSize = int64(size)
+	}
+
+	// Encoded contents can't be cut down, they would no longer decode
+	if setter.Base64 && (setter.Trim || setter.SingleLine) {
+		return nil, fmt.Errorf("'%s' for variable '%s' can't be used with '%s' or '%s'", FILE_BASE64_ENV_KEY, key, FILE_TRIM_ENV_KEY, FILE_SINGLE_LINE_ENV_KEY)
+	}
+
+	return setter, nil
+}
+
+func (s FileEnvironmentSetter) SetEnv() (string, error) {
+	fpath, err := s.resolvePath()
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(fpath)
+	switch {
+	case os.IsNotExist(err):
+		return "", fmt.Errorf("the file '%s' does not exist", fpath)
+	case err != nil:
+		return "", err
+	case info.IsDir():
+		return "", fmt.Errorf("'%s' is a directory, not a file", fpath)
+	case !info.Mode().IsRegular():
+		// Devices, pipes and sockets don't report their size so they could be read forever
+		return "", fmt.Errorf("'%s' is not a regular file", fpath)
+	case info.Size() > s.MaxSize:
+		return "", fmt.Errorf("the file '%s' is %d bytes, which is over the limit of %d bytes (raise it with '%s')", fpath, info.Size(), s.MaxSize, FILE_MAX_SIZE_ENV_KEY)
+	}
+
+	contents, err := readLimited(fpath, s.MaxSize)
+	if err != nil {
+		return "", err
+	}
+
+	if !s.AllowWorldReadable && isWorldReadable(info.Mode()) && isPrivateKey(fpath, contents) {
+		return "", fmt.Errorf("the key file '%s' can be read by everyone, restrict it (chmod 600) or set '%s: true'", fpath, FILE_ALLOW_WORLD_READABLE_ENV_KEY)
+	}
+
+	var val string
+	if s.Base64 {
+		val = base64.StdEncoding.EncodeToString(contents)
+	} else {
+		if !utf8.Valid(contents) || bytes.IndexByte(contents, 0) >= 0 {
+			return "", fmt.Errorf("the file '%s' has binary contents, set '%s: true' to encode them", fpath, FILE_BASE64_ENV_KEY)
+		}
+
+		val = string(contents)
+
+		if s.SingleLine {
+			val, _, _ = strings.Cut(val, "\n")
+			val = strings.TrimSuffix(val, "\r")
+		}
+
+		if s.Trim {
+			val = strings.TrimSpace(val)
+		}
+	}
+
+	return val, os.Setenv(s.EnvKey, val)
+}
+
+// readLimited will read the file, failing if it has more than max bytes (it grew after it was checked)
+func readLimited(fpath string, max int64) ([]byte, error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	contents, err := io.ReadAll(io.LimitReader(f, max+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(contents)) > max {
+		return nil, fmt.Errorf("the file '%s' is over the limit of %d bytes (raise it with '%s')", fpath, max, FILE_MAX_SIZE_ENV_KEY)
+	}
+
+	return contents, nil
+}
+
+// resolvePath will expand the path and resolve it from the config file's directory
+func (s FileEnvironmentSetter) resolvePath() (string, error) {
+	fpath := s.Path
+	if s.lookup != nil {
+		var err error
+		if fpath, err = interpolation.Expand(fpath, s.lookup); err != nil {
+			return "", err
+		}
+	}
+
+	return resolveFilePath(fpath, s.Dir), nil
+}
+
+// resolveFilePath will expand ~/ to the home directory and resolve a relative path from dir
+func resolveFilePath(fpath string, dir string) string {
+	fpath = fileio.ExpandHome(fpath)
+
+	if dir != "" && !filepath.IsAbs(fpath) {
+		fpath = filepath.Join(dir, fpath)
+	}
+
+	return fpath
+}
+
+// isWorldReadable will determine if every user can read the file, which isn't tracked on Windows
+func isWorldReadable(mode os.FileMode) bool {
+	return runtime.GOOS != "windows" && mode.Perm()&0004 != 0
+}
+
+// isPrivateKey will determine if the file holds a private key, by its PEM block or a .key extension
+func isPrivateKey(fpath string, contents []byte) bool {
+	return bytes.Contains(contents, []byte("PRIVATE KEY-----")) || strings.EqualFold(filepath.Ext(fpath), ".key")
+}
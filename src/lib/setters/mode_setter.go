@@ -0,0 +1,88 @@
+package setters
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+)
+
+const MODE_ENV_KEY = types.ENV_MODE_FIELD
+const SEPARATOR_ENV_KEY = "separator"
+
+// ModeEnvironmentSetter will prepend or append the value of another setter to the current value of the variable
+type ModeEnvironmentSetter struct {
+	Key       string
+	Mode      string
+	Separator string
+	Setter    EnvironmentSetter
+}
+
+func NewModeEnvironmentSetter(key string, mode string, separator string, setter EnvironmentSetter) *ModeEnvironmentSetter {
+	return &ModeEnvironmentSetter{
+		Key:       key,
+		Mode:      mode,
+		Separator: separator,
+		Setter:    setter,
+	}
+}
+
+func (s ModeEnvironmentSetter) SetEnv() (string, error) {
+	current, _ := os.LookupEnv(s.Key)
+
+	val, err := s.Setter.SetEnv()
+	if err != nil {
+		return "", err
+	}
+
+	// Nothing to add to
+	if current == "" {
+		return val, nil
+	}
+
+	switch s.Mode {
+	case types.ENV_MODE_PREPEND:
+		val = val + s.Separator + current
+	case types.ENV_MODE_APPEND:
+		val = current + s.Separator + val
+	}
+
+	return val, os.Setenv(s.Key, val)
+}
+
+// LayeredEnvironmentSetter will set the inherited value of a variable before the biome's own value
+type LayeredEnvironmentSetter struct {
+	Base   EnvironmentSetter
+	Setter EnvironmentSetter
+}
+
+func (s LayeredEnvironmentSetter) SetEnv() (string, error) {
+	if _, err := s.Base.SetEnv(); err != nil {
+		return "", err
+	}
+
+	return s.Setter.SetEnv()
+}
+
+// getSetterMode will read the mode and separator of a complex environment config
+func getSetterMode(key string, node map[string]interface{}) (string, string, error) {
+	mode := types.ENV_MODE_OVERRIDE
+	separator := string(os.PathListSeparator)
+
+	if val, exists := node[MODE_ENV_KEY]; exists {
+		var ok bool
+		if mode, ok = val.(string); !ok || !types.Contains(types.EnvModes, mode) {
+			return "", "", newSetterTypeError(key, MODE_ENV_KEY, fmt.Sprintf("one of %s", strings.Join(types.EnvModes, ", ")))
+		}
+	}
+
+	if val, exists := node[SEPARATOR_ENV_KEY]; exists {
+		var ok bool
+		if separator, ok = val.(string); !ok {
+			return "", "", newSetterTypeError(key, SEPARATOR_ENV_KEY, "a string")
+		}
+	}
+
+	return mode, separator, nil
+}
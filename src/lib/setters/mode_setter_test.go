@@ -0,0 +1,121 @@
+package setters
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeSetter(t *testing.T) {
+	testEnvKey := "FOOBAR_TEST_KEY"
+
+	// Helper for building a setter from a complex config
+	setEnv := func(t *testing.T, node interface{}) (string, error) {
+		setter, err := GetEnvironmentSetter(testEnvKey, node, SetterOptions{Lookup: os.LookupEnv})
+		if err != nil {
+			return "", err
+		}
+
+		return setter.SetEnv()
+	}
+
+	t.Cleanup(func() {
+		os.Unsetenv(testEnvKey)
+	})
+
+	for _, tc := range []struct {
+		name    string
+		current *string
+		node    map[string]interface{}
+		want    string
+	}{
+		{"should override the current value by default", strPtr("host"), map[string]interface{}{"value": "biome"}, "biome"},
+		{"should prepend to the current value", strPtr("/usr/bin"), map[string]interface{}{"value": "./bin", "mode": "prepend"}, "./bin:/usr/bin"},
+		{"should append with a custom separator", strPtr("--inspect"), map[string]interface{}{"value": "--max-old-space-size=4096", "mode": "append", "separator": " "}, "--inspect --max-old-space-size=4096"},
+		{"should not add a separator when there is no current value", nil, map[string]interface{}{"value": "./bin", "mode": "prepend"}, "./bin"},
+		{"should keep the current value in default mode", strPtr("warn"), map[string]interface{}{"value": "debug", "mode": "default"}, "warn"},
+		{"should set the value in default mode when there is no current value", nil, map[string]interface{}{"value": "debug", "mode": "default"}, "debug"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Assemble
+			os.Unsetenv(testEnvKey)
+			if tc.current != nil {
+				os.Setenv(testEnvKey, *tc.current)
+			}
+
+			// Act
+			val, err := setEnv(t, tc.node)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, val)
+			assert.Equal(t, tc.want, os.Getenv(testEnvKey))
+		})
+	}
+
+	t.Run("should not run the setter in default mode when there is a current value", func(t *testing.T) {
+		// Assemble
+		os.Setenv(testEnvKey, "host")
+
+		// Act
+		val, err := setEnv(t, map[string]interface{}{CLI_ENVIRONMENT_SETTER_KEY: true, MODE_ENV_KEY: types.ENV_MODE_DEFAULT})
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "host", val)
+	})
+
+	t.Run("should set the inherited config before the biome's own config", func(t *testing.T) {
+		// Assemble
+		os.Setenv(testEnvKey, "/usr/bin")
+		node := types.LayeredEnv{
+			Config: map[string]interface{}{"value": "./tools", "mode": "prepend"},
+			Base:   map[string]interface{}{"value": "./bin", "mode": "prepend"},
+		}
+
+		// Act
+		val, err := setEnv(t, node)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "./tools:./bin:/usr/bin", val)
+	})
+
+	t.Run("should resolve the inherited config's files from its own directory", func(t *testing.T) {
+		// Assemble
+		parentDir, childDir := t.TempDir(), t.TempDir()
+		assert.Nil(t, os.WriteFile(filepath.Join(parentDir, "name"), []byte("parent"), 0600))
+		assert.Nil(t, os.WriteFile(filepath.Join(childDir, "name"), []byte("child"), 0600))
+		os.Unsetenv(testEnvKey)
+
+		node := types.LayeredEnv{
+			Config:  map[string]interface{}{FILE_ENV_KEY: "name", "mode": "append"},
+			Base:    map[string]interface{}{FILE_ENV_KEY: "name", "mode": "append"},
+			BaseDir: parentDir,
+		}
+
+		// Act
+		setter, err := GetEnvironmentSetter(testEnvKey, node, SetterOptions{Dir: childDir})
+		assert.Nil(t, err)
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "parent:child", val)
+	})
+
+	t.Run("should report an unknown mode", func(t *testing.T) {
+		// Act
+		_, err := setEnv(t, map[string]interface{}{"value": "./bin", "mode": "sideways"})
+
+		// Assert
+		assert.ErrorContains(t, err, "'mode' for variable 'FOOBAR_TEST_KEY' must be one of override, default, prepend, append")
+	})
+}
+
+func strPtr(s string) *string {
+	return &s
+}
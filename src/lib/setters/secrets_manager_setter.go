@@ -1,39 +1,95 @@
 package setters
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/jeff-roche/biome/src/lib/interpolation"
 	"github.com/jeff-roche/biome/src/repos"
 )
 
 const SECRETS_MANAGER_ENV_ARN_KEY = "secret_arn"
 const SECRETS_MANAGER_ENV_JSON_KEY = "secret_json_key"
+const SECRETS_MANAGER_ENV_WHOLE_KEY = "secret_whole"
+const SECRETS_MANAGER_ENV_VERSION_STAGE_KEY = "secret_version_stage"
+const SECRETS_MANAGER_ENV_VERSION_ID_KEY = "secret_version_id"
 
 // SecretsManagerEnvironmentSetter will set an environment variable
-// from a JSON secret stored in AWS Secrets Manager
+// from a secret stored in AWS Secrets Manager
 type SecretsManagerEnvironmentSetter struct {
 	EnvKey    string                  // The environment variable key being set
-	ARN       string                  // The secrets manager secret ARN to reference
-	SecretKey string                  // The JSON key in the secret to use
+	ARN       string                  // The secrets manager secret ARN to reference, ${NAME} references are expanded
+	SecretKey string                  // The JSON key in the secret to use, nested keys are separated by . (db.hosts[0])
+	Whole     bool                    // Use the whole secret, binary secrets are base64 encoded
+	Version   repos.SecretVersion     // The version of the secret to use, the current one by default, ${NAME} references are expanded
 	repo      repos.SecretsManagerIfc // The Secrets manager client
+	lookup    Lookup
 }
 
 // NewSecretsManagerEnvironmentSetter will generete a SM Setter
-func NewSecretsManagerEnvironmentSetter(key string, subkeys map[string]interface{}) (*SecretsManagerEnvironmentSetter, error) {
+func NewSecretsManagerEnvironmentSetter(key string, subkeys map[string]interface{}, lookup Lookup) (*SecretsManagerEnvironmentSetter, error) {
 	setter := &SecretsManagerEnvironmentSetter{
 		EnvKey: key,
+		lookup: lookup,
 	}
 
 	// ARN
 	if val, exists := subkeys[SECRETS_MANAGER_ENV_ARN_KEY]; exists {
-		setter.ARN = val.(string)
+		arn, ok := val.(string)
+		if !ok {
+			return nil, newSetterTypeError(key, SECRETS_MANAGER_ENV_ARN_KEY, "a string")
+		}
+
+		setter.ARN = arn
 	}
 
 	// JSON Key
 	if val, exists := subkeys[SECRETS_MANAGER_ENV_JSON_KEY]; exists {
-		setter.SecretKey = val.(string)
+		secretKey, ok := val.(string)
+		if !ok {
+			return nil, newSetterTypeError(key, SECRETS_MANAGER_ENV_JSON_KEY, "a string")
+		}
+
+		setter.SecretKey = secretKey
+	}
+
+	// Whole Secret
+	if val, exists := subkeys[SECRETS_MANAGER_ENV_WHOLE_KEY]; exists {
+		whole, ok := val.(bool)
+		if !ok {
+			return nil, newSetterTypeError(key, SECRETS_MANAGER_ENV_WHOLE_KEY, "true or false")
+		}
+
+		setter.Whole = whole
+	}
+
+	if setter.Whole && setter.SecretKey != "" {
+		return nil, fmt.Errorf("'%s' for variable '%s' can't be used with '%s'", SECRETS_MANAGER_ENV_WHOLE_KEY, key, SECRETS_MANAGER_ENV_JSON_KEY)
+	}
+
+	// Version
+	if val, exists := subkeys[SECRETS_MANAGER_ENV_VERSION_STAGE_KEY]; exists {
+		stage, ok := val.(string)
+		if !ok || stage == "" {
+			return nil, newSetterTypeError(key, SECRETS_MANAGER_ENV_VERSION_STAGE_KEY, "a version stage")
+		}
+
+		setter.Version.Stage = stage
+	}
+
+	if val, exists := subkeys[SECRETS_MANAGER_ENV_VERSION_ID_KEY]; exists {
+		id, ok := val.(string)
+		if !ok || id == "" {
+			return nil, newSetterTypeError(key, SECRETS_MANAGER_ENV_VERSION_ID_KEY, "a version id")
+		}
+
+		setter.Version.Id = id
 	}
 
 	// Secrets Manager Repo
@@ -56,51 +112,190 @@ func (s SecretsManagerEnvironmentSetter) SetEnv() (string, error) {
 		)
 	}
 
-	secret, err := s.repo.GetSecretString(s.ARN)
+	if s.SecretKey == "" && !s.Whole {
+		return "", NewSecretsManagerEnvironmentSetterError(
+			s.EnvKey,
+			fmt.Sprintf("no JSON key for the secret was specified. Please use '%s' to specify one, or '%s: true' to use the whole secret", SECRETS_MANAGER_ENV_JSON_KEY, SECRETS_MANAGER_ENV_WHOLE_KEY),
+		)
+	}
+
+	arn, version := s.ARN, s.Version
+	if s.lookup != nil {
+		for _, field := range []*string{&arn, &version.Id, &version.Stage} {
+			var err error
+			if *field, err = interpolation.Expand(*field, s.lookup); err != nil {
+				return "", NewSecretsManagerEnvironmentSetterError(s.EnvKey, err.Error())
+			}
+		}
+	}
+
+	secret, err := s.repo.GetSecret(arn, version)
 	if err != nil {
 		return "", NewSecretsManagerEnvironmentSetterError(
 			s.EnvKey,
-			fmt.Sprintf("unable to load secret '%s': %v", s.ARN, err),
+			fmt.Sprintf("unable to load secret '%s': %v", arn, err),
 		)
 	}
 
 	// Validate the output
-	if secret == "" {
+	if secret.String == "" && len(secret.Binary) == 0 {
 		return "", NewSecretsManagerEnvironmentSetterError(
 			s.EnvKey,
-			fmt.Sprintf("only string based secrets are currently supported"),
+			fmt.Sprintf("secret '%s' is empty", arn),
 		)
 	}
 
-	// Get value from the JSON key
-	if s.SecretKey == "" {
+	var val string
+	if s.Whole {
+		val = secret.String
+		if len(secret.Binary) > 0 {
+			val = base64.StdEncoding.EncodeToString(secret.Binary)
+		}
+
+		return val, os.Setenv(s.EnvKey, val)
+	}
+
+	if len(secret.Binary) > 0 {
 		return "", NewSecretsManagerEnvironmentSetterError(
 			s.EnvKey,
-			fmt.Sprintf("no JSON key for the secret was specified. Please use '%s' to specify one", SECRETS_MANAGER_ENV_JSON_KEY),
+			fmt.Sprintf("secret '%s' is binary, set '%s: true' to use it base64 encoded", arn, SECRETS_MANAGER_ENV_WHOLE_KEY),
 		)
 	}
 
-	var jsonData map[string]string
-	if err := json.Unmarshal([]byte(secret), &jsonData); err != nil {
+	// Get value from the JSON key
+	jsonData, err := decodeSecretJSON(secret.String)
+	if err != nil {
 		return "", NewSecretsManagerEnvironmentSetterError(
 			s.EnvKey,
-			fmt.Sprintf("unable to parse secret '%s' JSON: %v", s.ARN, err),
+			fmt.Sprintf("unable to parse secret '%s' JSON: %v", arn, err),
 		)
 	}
 
 	// Check and set the key
-	val, exists := jsonData[s.SecretKey]
+	data, exists, err := lookupSecretKey(jsonData, s.SecretKey)
+	if err != nil {
+		return "", NewSecretsManagerEnvironmentSetterError(s.EnvKey, err.Error())
+	}
 
 	if !exists {
 		return "", NewSecretsManagerEnvironmentSetterError(
 			s.EnvKey,
-			fmt.Sprintf("secret '%s' does not contain JSON key '%s'", s.ARN, s.SecretKey),
+			fmt.Sprintf("secret '%s' does not contain JSON key '%s'", arn, s.SecretKey),
 		)
 	}
 
+	if val, err = SecretValueString(data); err != nil {
+		return "", NewSecretsManagerEnvironmentSetterError(s.EnvKey, err.Error())
+	}
+
 	return val, os.Setenv(s.EnvKey, val)
 }
 
+// SecretValueString will give the value of a decoded JSON secret as a string
+//
+// Strings are used as they are, anything else is compact JSON with the keys of objects sorted
+// and numbers as they were written
+func SecretValueString(val interface{}) (string, error) {
+	if str, ok := val.(string); ok {
+		return str, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(val); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// decodeSecretJSON will decode a JSON secret, numbers are kept as json.Number so they aren't rounded
+func decodeSecretJSON(secret string) (interface{}, error) {
+	var data interface{}
+
+	decoder := json.NewDecoder(strings.NewReader(secret))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+
+	return data, nil
+}
+
+// lookupSecretKey will find a key in a decoded JSON secret
+//
+// A key that exists in the top level object is used as is, otherwise it is a path of
+// keys separated by . with [N] for list items, e.g. db.hosts[0].name or $.db.port
+func lookupSecretKey(data interface{}, key string) (interface{}, bool, error) {
+	if obj, ok := data.(map[string]interface{}); ok {
+		if val, exists := obj[key]; exists {
+			return val, true, nil
+		}
+	}
+
+	path := strings.TrimPrefix(strings.TrimPrefix(key, "$"), ".")
+	if path == "" {
+		return nil, false, fmt.Errorf("invalid JSON key '%s'", key)
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		name := segment
+		var indexes []string
+
+		if start := strings.Index(segment, "["); start >= 0 {
+			name = segment[:start]
+
+			for rest := segment[start:]; rest != ""; {
+				end := strings.Index(rest, "]")
+				if rest[0] != '[' || end < 0 {
+					return nil, false, fmt.Errorf("invalid JSON key '%s'", key)
+				}
+
+				indexes = append(indexes, rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+
+		if name == "" && len(indexes) == 0 {
+			return nil, false, fmt.Errorf("invalid JSON key '%s'", key)
+		}
+
+		if name != "" {
+			obj, ok := data.(map[string]interface{})
+			if !ok {
+				return nil, false, nil
+			}
+
+			if data, ok = obj[name]; !ok {
+				return nil, false, nil
+			}
+		}
+
+		for _, index := range indexes {
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 {
+				return nil, false, fmt.Errorf("invalid JSON key '%s'", key)
+			}
+
+			list, ok := data.([]interface{})
+			if !ok || i >= len(list) {
+				return nil, false, nil
+			}
+
+			data = list[i]
+		}
+	}
+
+	return data, true, nil
+}
+
 type SecretsManagerEnvironmentSetterError struct {
 	varName string
 	value   string
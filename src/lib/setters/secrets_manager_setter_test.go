@@ -5,6 +5,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/jeff-roche/biome/src/repos"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -13,11 +14,17 @@ type mockSecretsManagerRepo struct {
 	mock.Mock
 }
 
-func (r mockSecretsManagerRepo) GetSecretString(val string) (string, error) {
+func (r *mockSecretsManagerRepo) GetSecretString(val string) (string, error) {
 	args := r.Called(val)
 	return args.String(0), args.Error(1)
 }
 
+func (r *mockSecretsManagerRepo) GetSecret(arn string, version repos.SecretVersion) (*repos.SecretValue, error) {
+	args := r.Called(arn, version)
+	secret, _ := args.Get(0).(*repos.SecretValue)
+	return secret, args.Error(1)
+}
+
 func TestSecretsManagerSetterBuilder(t *testing.T) {
 	t.Run("should set all the keys specified", func(t *testing.T) {
 		// Assemble
@@ -25,11 +32,14 @@ func TestSecretsManagerSetterBuilder(t *testing.T) {
 		configKeys := make(map[string]interface{})
 		configKeys[SECRETS_MANAGER_ENV_ARN_KEY] = "myArn"
 		configKeys[SECRETS_MANAGER_ENV_JSON_KEY] = "myJsonKey"
+		configKeys[SECRETS_MANAGER_ENV_VERSION_STAGE_KEY] = "AWSPREVIOUS"
+		configKeys[SECRETS_MANAGER_ENV_VERSION_ID_KEY] = "myVersionId"
 
 		// Act
 		setter, err := NewSecretsManagerEnvironmentSetter(
 			envKey,
 			configKeys,
+			nil,
 		)
 
 		// Assert
@@ -37,9 +47,36 @@ func TestSecretsManagerSetterBuilder(t *testing.T) {
 		assert.Equal(t, envKey, setter.EnvKey)
 		assert.Equal(t, configKeys[SECRETS_MANAGER_ENV_ARN_KEY], setter.ARN)
 		assert.Equal(t, configKeys[SECRETS_MANAGER_ENV_JSON_KEY], setter.SecretKey)
+		assert.Equal(t, repos.SecretVersion{Id: "myVersionId", Stage: "AWSPREVIOUS"}, setter.Version)
+		assert.False(t, setter.Whole)
 		assert.NotNil(t, setter.repo)
 	})
 
+	t.Run("should not allow the whole secret with a JSON key", func(t *testing.T) {
+		// Assemble
+		configKeys := map[string]interface{}{
+			SECRETS_MANAGER_ENV_ARN_KEY:   "myArn",
+			SECRETS_MANAGER_ENV_JSON_KEY:  "myJsonKey",
+			SECRETS_MANAGER_ENV_WHOLE_KEY: true,
+		}
+
+		// Act
+		_, err := NewSecretsManagerEnvironmentSetter("MY_ENV_VAR", configKeys, nil)
+
+		// Assert
+		assert.EqualError(t, err, "'secret_whole' for variable 'MY_ENV_VAR' can't be used with 'secret_json_key'")
+	})
+
+	t.Run("should report keys of the wrong type", func(t *testing.T) {
+		// Act
+		_, wholeErr := NewSecretsManagerEnvironmentSetter("MY_ENV_VAR", map[string]interface{}{SECRETS_MANAGER_ENV_WHOLE_KEY: "yes"}, nil)
+		_, stageErr := NewSecretsManagerEnvironmentSetter("MY_ENV_VAR", map[string]interface{}{SECRETS_MANAGER_ENV_VERSION_STAGE_KEY: 3}, nil)
+
+		// Assert
+		assert.EqualError(t, wholeErr, "'secret_whole' for variable 'MY_ENV_VAR' must be true or false")
+		assert.EqualError(t, stageErr, "'secret_version_stage' for variable 'MY_ENV_VAR' must be a version stage")
+	})
+
 	t.Run("should not set keys that are not specified", func(t *testing.T) {
 		// Assemble
 		envKey := "MY_ENV_VAR"
@@ -49,12 +86,14 @@ func TestSecretsManagerSetterBuilder(t *testing.T) {
 		setter, err := NewSecretsManagerEnvironmentSetter(
 			envKey,
 			configKeys,
+			nil,
 		)
 
 		// Assert
 		assert.Nil(t, err)
 		assert.Empty(t, setter.ARN)
 		assert.Empty(t, setter.SecretKey)
+		assert.Empty(t, setter.Version)
 		assert.NotNil(t, setter.repo)
 	})
 }
@@ -79,7 +118,7 @@ func TestSecretsManagerSetter(t *testing.T) {
 	t.Run("should set the env var from the returned JSON", func(t *testing.T) {
 		// Assemble
 		mockRepo := &mockSecretsManagerRepo{}
-		mockRepo.On("GetSecretString", testARN).Return(testJSON, nil)
+		mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{String: testJSON}, nil)
 		setter := getTestSetter(mockRepo)
 
 		t.Cleanup(func() {
@@ -113,7 +152,7 @@ func TestSecretsManagerSetter(t *testing.T) {
 		// Assemble
 		customErr := "unable to get secret"
 		mockRepo := &mockSecretsManagerRepo{}
-		mockRepo.On("GetSecretString", testARN).Return("", fmt.Errorf(customErr))
+		mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(nil, fmt.Errorf(customErr))
 		setter := getTestSetter(mockRepo)
 
 		// Act
@@ -127,7 +166,7 @@ func TestSecretsManagerSetter(t *testing.T) {
 	t.Run("should report an error if no secret is returned", func(t *testing.T) {
 		// Assemble
 		mockRepo := &mockSecretsManagerRepo{}
-		mockRepo.On("GetSecretString", testARN).Return("", nil)
+		mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{}, nil)
 		setter := getTestSetter(mockRepo)
 
 		// Act
@@ -141,7 +180,7 @@ func TestSecretsManagerSetter(t *testing.T) {
 	t.Run("should report an error if no JSON key is provided", func(t *testing.T) {
 		// Assemble
 		mockRepo := &mockSecretsManagerRepo{}
-		mockRepo.On("GetSecretString", testARN).Return(testJSON, nil)
+		mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{String: testJSON}, nil)
 		setter := getTestSetter(mockRepo)
 		setter.SecretKey = ""
 
@@ -157,7 +196,7 @@ func TestSecretsManagerSetter(t *testing.T) {
 	t.Run("should report an error if invalid JSON is returned", func(t *testing.T) {
 		// Assemble
 		mockRepo := &mockSecretsManagerRepo{}
-		mockRepo.On("GetSecretString", testARN).Return("I'm Not Valid }", nil)
+		mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{String: "I'm Not Valid }"}, nil)
 		setter := getTestSetter(mockRepo)
 
 		// Act
@@ -172,7 +211,7 @@ func TestSecretsManagerSetter(t *testing.T) {
 	t.Run("should report an error if the specified JSON key does not exist", func(t *testing.T) {
 		// Assemble
 		mockRepo := &mockSecretsManagerRepo{}
-		mockRepo.On("GetSecretString", testARN).Return(testJSON, nil)
+		mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{String: testJSON}, nil)
 		setter := getTestSetter(mockRepo)
 		setter.SecretKey = "invalidKey"
 
@@ -184,4 +223,197 @@ func TestSecretsManagerSetter(t *testing.T) {
 		assert.Equal(t, val, "")
 		assert.ErrorContains(t, err, "does not contain JSON key")
 	})
+	t.Run("should request the version of the secret", func(t *testing.T) {
+		// Assemble
+		version := repos.SecretVersion{Stage: "AWSPREVIOUS"}
+		mockRepo := &mockSecretsManagerRepo{}
+		mockRepo.On("GetSecret", testARN, version).Return(&repos.SecretValue{String: `{"mykey": "previous"}`}, nil)
+		setter := getTestSetter(mockRepo)
+		setter.Version = version
+
+		t.Cleanup(func() {
+			os.Unsetenv(testEnv)
+		})
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "previous", val)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should expand references in the ARN and version", func(t *testing.T) {
+		// Assemble
+		lookup := func(name string) (string, bool) {
+			return map[string]string{"STAGE": "prod", "RELEASE": "AWSPENDING"}[name], true
+		}
+
+		mockRepo := &mockSecretsManagerRepo{}
+		mockRepo.On("GetSecret", "app/prod", repos.SecretVersion{Stage: "AWSPENDING"}).Return(&repos.SecretValue{String: testJSON}, nil)
+		setter := getTestSetter(mockRepo)
+		setter.ARN = "app/${STAGE}"
+		setter.Version = repos.SecretVersion{Stage: "${RELEASE}"}
+		setter.lookup = lookup
+
+		t.Cleanup(func() {
+			os.Unsetenv(testEnv)
+		})
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, testJSONValue, val)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should set the whole secret", func(t *testing.T) {
+		// Assemble
+		mockRepo := &mockSecretsManagerRepo{}
+		mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{String: "hunter2"}, nil)
+		setter := getTestSetter(mockRepo)
+		setter.SecretKey = ""
+		setter.Whole = true
+
+		t.Cleanup(func() {
+			os.Unsetenv(testEnv)
+		})
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "hunter2", val)
+		assert.Equal(t, "hunter2", os.Getenv(testEnv))
+	})
+
+	t.Run("should set a binary secret base64 encoded", func(t *testing.T) {
+		// Assemble
+		mockRepo := &mockSecretsManagerRepo{}
+		mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{Binary: []byte("\x00binary")}, nil)
+		setter := getTestSetter(mockRepo)
+		setter.SecretKey = ""
+		setter.Whole = true
+
+		t.Cleanup(func() {
+			os.Unsetenv(testEnv)
+		})
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "AGJpbmFyeQ==", val)
+	})
+
+	t.Run("should report an error using a JSON key with a binary secret", func(t *testing.T) {
+		// Assemble
+		mockRepo := &mockSecretsManagerRepo{}
+		mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{Binary: []byte("binary")}, nil)
+		setter := getTestSetter(mockRepo)
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Equal(t, "", val)
+		assert.ErrorContains(t, err, "secret 'myARN' is binary, set 'secret_whole: true' to use it base64 encoded")
+	})
+
+	t.Run("should find nested JSON keys", func(t *testing.T) {
+		// Assemble
+		secret := `{"db": {"hosts": [{"name": "primary"}, {"name": "replica"}], "port": 5432}, "db.user": "admin"}`
+		tests := map[string]string{
+			"db.hosts[1].name":   "replica",
+			"$.db.hosts[0].name": "primary",
+			"db.port":            "5432",
+			"db.user":            "admin",
+		}
+
+		t.Cleanup(func() {
+			os.Unsetenv(testEnv)
+		})
+
+		for key, expected := range tests {
+			mockRepo := &mockSecretsManagerRepo{}
+			mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{String: secret}, nil)
+			setter := getTestSetter(mockRepo)
+			setter.SecretKey = key
+
+			// Act
+			val, err := setter.SetEnv()
+
+			// Assert
+			assert.Nil(t, err, key)
+			assert.Equal(t, expected, val, key)
+		}
+	})
+
+	t.Run("should report missing and invalid nested JSON keys", func(t *testing.T) {
+		// Assemble
+		mockRepo := &mockSecretsManagerRepo{}
+		mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{String: `{"db": {"hosts": ["primary"]}}`}, nil)
+		setter := getTestSetter(mockRepo)
+
+		// Act
+		setter.SecretKey = "db.hosts[3]"
+		_, missingErr := setter.SetEnv()
+		setter.SecretKey = "db.hosts[x]"
+		_, invalidErr := setter.SetEnv()
+
+		// Assert
+		assert.ErrorContains(t, missingErr, "secret 'myARN' does not contain JSON key 'db.hosts[3]'")
+		assert.ErrorContains(t, invalidErr, "invalid JSON key 'db.hosts[x]'")
+	})
+
+	t.Run("should render values that aren't strings as JSON", func(t *testing.T) {
+		// Assemble
+		secret := `{"port": 5432, "ratio": 1.50, "big": 12345678901234567890, "debug": true, "empty": null, "tags": ["a", "<b>"], "db": {"z": 1, "a": {"x": false}}}`
+		tests := map[string]string{
+			"port":  "5432",
+			"ratio": "1.50",
+			"big":   "12345678901234567890",
+			"debug": "true",
+			"empty": "null",
+			"tags":  `["a","<b>"]`,
+			"db":    `{"a":{"x":false},"z":1}`,
+		}
+
+		t.Cleanup(func() {
+			os.Unsetenv(testEnv)
+		})
+
+		for key, expected := range tests {
+			mockRepo := &mockSecretsManagerRepo{}
+			mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{String: secret}, nil)
+			setter := getTestSetter(mockRepo)
+			setter.SecretKey = key
+
+			// Act
+			val, err := setter.SetEnv()
+
+			// Assert
+			assert.Nil(t, err, key)
+			assert.Equal(t, expected, val, key)
+		}
+	})
+
+	t.Run("should report an error for a plain string secret with a JSON key", func(t *testing.T) {
+		// Assemble
+		mockRepo := &mockSecretsManagerRepo{}
+		mockRepo.On("GetSecret", testARN, repos.SecretVersion{}).Return(&repos.SecretValue{String: `"hunter2" trailing`}, nil)
+		setter := getTestSetter(mockRepo)
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Equal(t, "", val)
+		assert.ErrorContains(t, err, "unable to parse secret")
+	})
 }
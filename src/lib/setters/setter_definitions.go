@@ -0,0 +1,222 @@
+package setters
+
+import "github.com/jeff-roche/biome/src/lib/types"
+
+// SetterDefinitions describes every complex setter in the order getComplexSetter checks for them
+//
+// This is used to validate config files and to generate the JSON Schema, so every key a setter
+// reads from its config needs to be listed here
+var SetterDefinitions = []types.SetterDefinition{
+	{
+		Description: "Set a value, used to give a value a mode or to encode a list or map",
+		Selector: types.SetterKey{
+			Name:        VALUE_ENV_KEY,
+			Type:        types.SETTER_VALUE_ANY,
+			Description: "The value to set, ${NAME} references are expanded. Lists and maps need an encoding",
+		},
+		Keys: withCommonKeys(
+			types.SetterKey{
+				Name:        ENCODE_ENV_KEY,
+				Type:        types.SETTER_VALUE_STRING,
+				Enum:        Encodings,
+				Description: "How a list (json, csv or join) or a map (json or yaml) is encoded into the value",
+			},
+		),
+	},
+	{
+		Description: "Load the value from a secret in AWS Secrets Manager",
+		Selector: types.SetterKey{
+			Name:        SECRETS_MANAGER_ENV_ARN_KEY,
+			Type:        types.SETTER_VALUE_STRING,
+			Description: "The ARN (or name) of the secret, ${NAME} references are expanded",
+		},
+		Keys: withCommonKeys(
+			types.SetterKey{
+				Name:        SECRETS_MANAGER_ENV_JSON_KEY,
+				Type:        types.SETTER_VALUE_STRING,
+				Description: "The key in the secret's JSON to use as the value, nested keys are separated by . with [N] for list items",
+			},
+			types.SetterKey{
+				Name:        SECRETS_MANAGER_ENV_WHOLE_KEY,
+				Type:        types.SETTER_VALUE_BOOL,
+				Description: "Use the whole secret as the value instead of a JSON key, binary secrets are base64 encoded",
+			},
+			types.SetterKey{
+				Name:        SECRETS_MANAGER_ENV_VERSION_STAGE_KEY,
+				Type:        types.SETTER_VALUE_STRING,
+				Description: "The staging label of the version to use (AWSCURRENT by default)",
+			},
+			types.SetterKey{
+				Name:        SECRETS_MANAGER_ENV_VERSION_ID_KEY,
+				Type:        types.SETTER_VALUE_STRING,
+				Description: "The id of the version to use",
+			},
+		),
+		ExactlyOne: []string{SECRETS_MANAGER_ENV_JSON_KEY, SECRETS_MANAGER_ENV_WHOLE_KEY},
+		Secret:     true,
+	},
+	{
+		Description: "Load the value from a parameter in AWS Systems Manager Parameter Store",
+		Selector: types.SetterKey{
+			Name:        SSM_ENV_KEY,
+			Type:        types.SETTER_VALUE_STRING,
+			Description: "The name of the parameter, ${NAME} references are expanded",
+		},
+		Keys: withCommonKeys(
+			types.SetterKey{
+				Name:        SSM_DECRYPT_ENV_KEY,
+				Type:        types.SETTER_VALUE_BOOL,
+				Description: "Decrypt a SecureString parameter, biome show redacts the value",
+			},
+			types.SetterKey{
+				Name:        SSM_VERSION_ENV_KEY,
+				Type:        types.SETTER_VALUE_SCALAR,
+				Description: "The version of the parameter to use, the latest by default",
+			},
+			types.SetterKey{
+				Name:        SSM_LABEL_ENV_KEY,
+				Type:        types.SETTER_VALUE_STRING,
+				Description: "The label of the parameter version to use",
+			},
+		),
+	},
+	{
+		Description: "Decrypt a value that was encrypted with dragoman",
+		Selector: types.SetterKey{
+			Name:        DRAGOMAN_ENV_KEY,
+			Type:        types.SETTER_VALUE_STRING,
+			Description: "The dragoman encrypted value ([ENC,...])",
+		},
+		Keys:   withCommonKeys(),
+		Secret: true,
+	},
+	{
+		Description: "Render the value from a go text/template",
+		Selector: types.SetterKey{
+			Name:        TEMPLATE_ENV_KEY,
+			Type:        types.SETTER_VALUE_STRING,
+			Description: "The template to render, see the README for the available functions",
+		},
+		Keys: withCommonKeys(),
+	},
+	{
+		Description: "Read the value from a file, such as a certificate, a key or a Docker secret",
+		Selector: types.SetterKey{
+			Name:        FILE_ENV_KEY,
+			Type:        types.SETTER_VALUE_STRING,
+			Description: "The file to read, relative to the config file (~/ is the home directory), ${NAME} references are expanded",
+		},
+		Keys: withCommonKeys(
+			types.SetterKey{
+				Name:        FILE_TRIM_ENV_KEY,
+				Type:        types.SETTER_VALUE_BOOL,
+				Description: "Remove the whitespace (ex: a trailing newline) around the contents",
+			},
+			types.SetterKey{
+				Name:        FILE_SINGLE_LINE_ENV_KEY,
+				Type:        types.SETTER_VALUE_BOOL,
+				Description: "Only use the first line of the file",
+			},
+			types.SetterKey{
+				Name:        FILE_BASE64_ENV_KEY,
+				Type:        types.SETTER_VALUE_BOOL,
+				Description: "Encode the contents with base64, for binary files",
+			},
+			types.SetterKey{
+				Name:        FILE_MAX_SIZE_ENV_KEY,
+				Type:        types.SETTER_VALUE_SCALAR,
+				Description: "The largest file in bytes that will be read (1048576 by default)",
+			},
+			types.SetterKey{
+				Name:        FILE_ALLOW_WORLD_READABLE_ENV_KEY,
+				Type:        types.SETTER_VALUE_BOOL,
+				Description: "Read a private key even if everyone can read it",
+			},
+		),
+		Secret: true,
+	},
+	{
+		Description: "Set the value to the output of a command",
+		Selector: types.SetterKey{
+			Name:        COMMAND_ENV_KEY,
+			Type:        types.SETTER_VALUE_ANY,
+			Description: "The command and its arguments as a list, or a string run with the shell when shell is true. ${NAME} references are expanded",
+		},
+		Keys: withCommonKeys(
+			types.SetterKey{
+				Name:        COMMAND_SHELL_ENV_KEY,
+				Type:        types.SETTER_VALUE_BOOL,
+				Description: "Run the command string with the shell (sh -c, or cmd /C on Windows)",
+			},
+			types.SetterKey{
+				Name:        COMMAND_TIMEOUT_ENV_KEY,
+				Type:        types.SETTER_VALUE_SCALAR,
+				Description: "How long the command can run for, a duration (ex: 30s) or a number of seconds. No limit by default",
+			},
+			types.SetterKey{
+				Name:        COMMAND_TRIM_ENV_KEY,
+				Type:        types.SETTER_VALUE_BOOL,
+				Description: "Remove all of the whitespace around the output, trailing newlines are always removed",
+			},
+			types.SetterKey{
+				Name:        COMMAND_SECRET_ENV_KEY,
+				Type:        types.SETTER_VALUE_BOOL,
+				Description: "The output is a secret, biome show redacts it and biome diff hashes it",
+			},
+		),
+	},
+	{
+		Description: "Prompt for the value on the command line",
+		Selector: types.SetterKey{
+			Name:        CLI_ENVIRONMENT_SETTER_KEY,
+			Type:        types.SETTER_VALUE_BOOL,
+			Const:       true,
+			Description: "Prompt for the value when the biome is activated",
+		},
+		Keys: withCommonKeys(
+			types.SetterKey{
+				Name:        CLI_ENVIRONMENT_SECRET_SETTER_KEY,
+				Type:        types.SETTER_VALUE_BOOL,
+				Description: "Hide the input (for passwords)",
+			},
+		),
+	},
+}
+
+// withCommonKeys will add the keys every setter accepts, the mode and separator that combine its value with
+// the current value and the metadata that documents the variable (description, example, required and deprecated)
+func withCommonKeys(keys ...types.SetterKey) []types.SetterKey {
+	return append(keys,
+		types.SetterKey{
+			Name:        MODE_ENV_KEY,
+			Type:        types.SETTER_VALUE_STRING,
+			Enum:        types.EnvModes,
+			Description: "override (default) the current value, only set the value by default if it isn't set, or prepend or append to it",
+		},
+		types.SetterKey{
+			Name:        SEPARATOR_ENV_KEY,
+			Type:        types.SETTER_VALUE_STRING,
+			Description: "The separator used to prepend or append (: by default, ; on Windows), or by the csv and join encodings (, by default)",
+		},
+		types.SetterKey{
+			Name:        types.ENV_DESCRIPTION_FIELD,
+			Type:        types.SETTER_VALUE_STRING,
+			Description: "What the variable is for, shown by biome docs",
+		},
+		types.SetterKey{
+			Name:        types.ENV_EXAMPLE_FIELD,
+			Type:        types.SETTER_VALUE_SCALAR,
+			Description: "An example value, shown by biome docs",
+		},
+		types.SetterKey{
+			Name:        types.ENV_REQUIRED_FIELD,
+			Type:        types.SETTER_VALUE_BOOL,
+			Description: "Fail to activate the biome when the value is empty",
+		},
+		types.SetterKey{
+			Name:        types.ENV_DEPRECATED_FIELD,
+			Type:        types.SETTER_VALUE_SCALAR,
+			Description: "true, or a message such as the variable to use instead, to warn when the biome is activated",
+		},
+	)
+}
@@ -0,0 +1,65 @@
+package setters
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetterDefinitions(t *testing.T) {
+	t.Run("should define every setter key in the package", func(t *testing.T) {
+		// Assemble
+		defined := make(map[string]bool)
+		for _, def := range SetterDefinitions {
+			defined[def.Selector.Name] = true
+
+			for _, key := range def.Keys {
+				defined[key.Name] = true
+			}
+		}
+
+		// Every string constant ending in _KEY is a key read from a complex environment config
+		fset := token.NewFileSet()
+		pkgs, err := parser.ParseDir(fset, ".", func(fi fs.FileInfo) bool {
+			return !strings.HasSuffix(fi.Name(), "_test.go")
+		}, 0)
+		assert.Nil(t, err)
+
+		var keys []string
+		for _, pkg := range pkgs {
+			for _, file := range pkg.Files {
+				ast.Inspect(file, func(n ast.Node) bool {
+					spec, ok := n.(*ast.ValueSpec)
+					if !ok {
+						return true
+					}
+
+					for i, name := range spec.Names {
+						if !strings.HasSuffix(name.Name, "_KEY") || i >= len(spec.Values) {
+							continue
+						}
+
+						if lit, ok := spec.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							val, _ := strconv.Unquote(lit.Value)
+							keys = append(keys, val)
+						}
+					}
+
+					return true
+				})
+			}
+		}
+
+		// Assert
+		assert.NotEmpty(t, keys)
+		for _, key := range keys {
+			assert.True(t, defined[key], "setter key '%s' is missing from SetterDefinitions", key)
+		}
+	})
+}
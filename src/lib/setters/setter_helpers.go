@@ -3,48 +3,253 @@ package setters
 import (
 	"fmt"
 	"os"
+	"strings"
+
+	"github.com/jeff-roche/biome/src/lib/interpolation"
+	"github.com/jeff-roche/biome/src/lib/types"
 )
 
-func GetEnvironmentSetter(key string, node interface{}) (EnvironmentSetter, error) {
+// SetterOptions controls how the setter for an environment config is built
+type SetterOptions struct {
+	Lookup Lookup             // Expands ${NAME} references in string values, without one the values are taken as is
+	Dir    string             // The directory of the config file the variable is declared in, relative file paths are resolved from it
+	Ssm    *SsmParameterBatch // The batch Parameter Store parameters are loaded with, each is loaded on its own without one
+	Biome  string             // The name of the active biome, given to templates
+	Cli    *CliInput          // The values entered up front for the CLI setters, they prompt when they are built without one
+}
+
+// GetEnvironmentSetter will build the setter for the environment config
+func GetEnvironmentSetter(key string, node interface{}, opts SetterOptions) (EnvironmentSetter, error) {
 	var setter EnvironmentSetter
-	switch node.(type) {
+	switch val := node.(type) {
 	case map[string]interface{}: // Complex keys
-		var err error
-		setter, err = getComplexSetter(key, node.(map[string]interface{}))
+		mode, separator, err := getSetterMode(key, val)
 		if err != nil {
 			return nil, err
 		}
+
+		// Keep the current value without running the setter (no prompts or AWS calls)
+		if current, exists := os.LookupEnv(key); exists && mode == types.ENV_MODE_DEFAULT {
+			return NewBasicEnvironmentSetter(key, current), nil
+		}
+
+		setter, err = getComplexSetter(key, val, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if mode == types.ENV_MODE_PREPEND || mode == types.ENV_MODE_APPEND {
+			setter = NewModeEnvironmentSetter(key, mode, separator, setter)
+		}
+	case types.LayeredEnv: // Builds on an inherited config
+		// Relative paths in the inherited config are relative to the file it was declared in
+		baseOpts := opts
+		if val.BaseDir != "" {
+			baseOpts.Dir = val.BaseDir
+		}
+
+		base, err := GetEnvironmentSetter(key, val.Base, baseOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		top, err := GetEnvironmentSetter(key, val.Config, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		setter = &LayeredEnvironmentSetter{Base: base, Setter: top}
+	case []interface{}: // Lists need an explicit encoding
+		return nil, fmt.Errorf("variable '%s' is a list, use '%s' with '%s' to encode it", key, VALUE_ENV_KEY, ENCODE_ENV_KEY)
 	default: // basic types
-		setter = NewBasicEnvironmentSetter(key, node)
+		setter = newValueSetter(key, val, opts.Lookup)
 	}
 
 	return setter, nil
 }
 
-func getComplexSetter(key string, node map[string]interface{}) (EnvironmentSetter, error) {
+// GetReferences will return the names of the variables the environment config reads from
+func GetReferences(node interface{}) []string {
+	switch val := node.(type) {
+	case string:
+		return interpolation.References(val)
+	case map[string]interface{}:
+		if tmpl, ok := val[TEMPLATE_ENV_KEY].(string); ok {
+			return TemplateReferences(tmpl)
+		}
+
+		if fpath, ok := val[FILE_ENV_KEY].(string); ok {
+			return interpolation.References(fpath)
+		}
+
+		if arn, ok := val[SECRETS_MANAGER_ENV_ARN_KEY].(string); ok {
+			id, _ := val[SECRETS_MANAGER_ENV_VERSION_ID_KEY].(string)
+			stage, _ := val[SECRETS_MANAGER_ENV_VERSION_STAGE_KEY].(string)
+
+			refs := append(interpolation.References(arn), interpolation.References(id)...)
+			return append(refs, interpolation.References(stage)...)
+		}
+
+		if name, ok := val[SSM_ENV_KEY].(string); ok {
+			return interpolation.References(name)
+		}
+
+		if command, exists := val[COMMAND_ENV_KEY]; exists {
+			return valueReferences(command)
+		}
+
+		return valueReferences(val[VALUE_ENV_KEY])
+	case types.LayeredEnv:
+		return append(GetReferences(val.Base), GetReferences(val.Config)...)
+	}
+
+	return nil
+}
+
+// SetterType will return the key that selects the setter for the environment config, basic values are a value
+func SetterType(node interface{}) string {
+	switch val := node.(type) {
+	case map[string]interface{}:
+		for _, def := range SetterDefinitions {
+			if _, exists := val[def.Selector.Name]; exists {
+				return def.Selector.Name
+			}
+		}
+
+		return ""
+	case types.LayeredEnv:
+		return SetterType(val.Config)
+	}
+
+	return VALUE_ENV_KEY
+}
+
+// IsSecret will determine if the environment config sets a secret, such as a Secrets Manager
+// value, a decrypted parameter, a hidden CLI input or a command marked as secret
+func IsSecret(node interface{}) bool {
+	switch val := node.(type) {
+	case map[string]interface{}:
+		if hidden, _ := val[CLI_ENVIRONMENT_SECRET_SETTER_KEY].(bool); hidden {
+			return true
+		}
+
+		if secret, _ := val[COMMAND_SECRET_ENV_KEY].(bool); secret {
+			return true
+		}
+
+		if decrypt, _ := val[SSM_DECRYPT_ENV_KEY].(bool); decrypt {
+			return true
+		}
+
+		for _, def := range SetterDefinitions {
+			if _, exists := val[def.Selector.Name]; exists {
+				return def.Secret
+			}
+		}
+	case types.LayeredEnv:
+		return IsSecret(val.Base) || IsSecret(val.Config)
+	}
+
+	return false
+}
+
+// newValueSetter will build the setter for a basic value, strings are interpolated when there is a lookup
+func newValueSetter(key string, val interface{}, lookup Lookup) EnvironmentSetter {
+	if str, ok := val.(string); ok && lookup != nil {
+		return NewInterpolatedEnvironmentSetter(key, str, lookup)
+	}
+
+	return NewBasicEnvironmentSetter(key, val)
+}
+
+func getComplexSetter(key string, node map[string]interface{}, opts SetterOptions) (EnvironmentSetter, error) {
+	lookup := opts.Lookup
+
+	// Basic value
+	if val, exists := node[VALUE_ENV_KEY]; exists {
+		if _, exists := node[ENCODE_ENV_KEY]; exists {
+			return NewEncodedEnvironmentSetter(key, node, lookup)
+		}
+
+		switch val.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("'%s' for variable '%s' is a list or map, set '%s' to one of %s to encode it",
+				VALUE_ENV_KEY, key, ENCODE_ENV_KEY, strings.Join(Encodings, ", "))
+		case nil:
+			return nil, newSetterTypeError(key, VALUE_ENV_KEY, "a string, number, boolean, list or map")
+		}
+
+		return newValueSetter(key, val, lookup), nil
+	}
+
 	// Secrets Manager Secret
 	if _, exists := node[SECRETS_MANAGER_ENV_ARN_KEY]; exists {
+		return NewSecretsManagerEnvironmentSetter(key, node, lookup)
+	}
 
-		return NewSecretsManagerEnvironmentSetter(key, node)
+	// Parameter Store Parameter
+	if _, exists := node[SSM_ENV_KEY]; exists {
+		return NewSsmEnvironmentSetter(key, node, lookup, opts.Ssm)
 	}
 
 	// Dragoman Encrypted Secret
 	if val, exists := node[DRAGOMAN_ENV_KEY]; exists {
-		return NewDragomanEnvironmentSetter(key, val.(string))
+		encrypted, ok := val.(string)
+		if !ok {
+			return nil, newSetterTypeError(key, DRAGOMAN_ENV_KEY, "a string")
+		}
+
+		return NewDragomanEnvironmentSetter(key, encrypted)
+	}
+
+	// Go Template
+	if val, exists := node[TEMPLATE_ENV_KEY]; exists {
+		tmpl, ok := val.(string)
+		if !ok {
+			return nil, newSetterTypeError(key, TEMPLATE_ENV_KEY, "a string")
+		}
+
+		return NewTemplateEnvironmentSetter(key, tmpl, opts.Biome, opts.Dir), nil
+	}
+
+	// File Contents
+	if _, exists := node[FILE_ENV_KEY]; exists {
+		return NewFileEnvironmentSetter(key, node, lookup, opts.Dir)
+	}
+
+	// Command Output
+	if _, exists := node[COMMAND_ENV_KEY]; exists {
+		return NewCommandEnvironmentSetter(key, node, lookup)
 	}
 
 	// CLI Input
-	if val, exists := node[CLI_ENVIRONMENT_SETTER_KEY]; exists && val.(bool) {
+	if val, exists := node[CLI_ENVIRONMENT_SETTER_KEY]; exists {
+		fromCli, ok := val.(bool)
+		if !ok {
+			return nil, newSetterTypeError(key, CLI_ENVIRONMENT_SETTER_KEY, "true or false")
+		}
+
 		var isSecret bool
 
-		if _, exists := node[CLI_ENVIRONMENT_SECRET_SETTER_KEY]; exists {
-			isSecret = node[CLI_ENVIRONMENT_SECRET_SETTER_KEY].(bool)
-		} else {
-			isSecret = false
+		if val, exists := node[CLI_ENVIRONMENT_SECRET_SETTER_KEY]; exists {
+			if isSecret, ok = val.(bool); !ok {
+				return nil, newSetterTypeError(key, CLI_ENVIRONMENT_SECRET_SETTER_KEY, "true or false")
+			}
 		}
 
-		return NewCLIEnvironmentSetter(key, os.Stdin, isSecret)
+		if fromCli && opts.Cli != nil {
+			return opts.Cli.newSetter(key, isSecret)
+		}
+
+		if fromCli {
+			return NewCLIEnvironmentSetter(key, os.Stdin, isSecret)
+		}
 	}
 
 	return nil, fmt.Errorf("unkown environment config for variable '%s'", key)
 }
+
+// newSetterTypeError reports a complex environment config key with the wrong type of value
+func newSetterTypeError(key string, setterKey string, expected string) error {
+	return fmt.Errorf("'%s' for variable '%s' must be %s", setterKey, key, expected)
+}
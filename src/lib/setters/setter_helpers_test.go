@@ -0,0 +1,93 @@
+package setters
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEnvironmentSetter(t *testing.T) {
+	testEnvKey := "FOOBAR_TEST_KEY"
+
+	t.Run("should build a basic setter for basic values", func(t *testing.T) {
+		setter, err := GetEnvironmentSetter(testEnvKey, "value", SetterOptions{})
+
+		assert.Nil(t, err)
+		assert.IsType(t, &BasicEnvironmentSetter{}, setter)
+	})
+
+	t.Run("should interpolate basic values when there is a lookup", func(t *testing.T) {
+		lookup := func(name string) (string, bool) { return "world", name == "NAME" }
+
+		for _, node := range []interface{}{"hello ${NAME}", map[string]interface{}{VALUE_ENV_KEY: "hello ${NAME}"}} {
+			setter, err := GetEnvironmentSetter(testEnvKey, node, SetterOptions{Lookup: lookup})
+			assert.Nil(t, err)
+
+			val, err := setter.SetEnv()
+			assert.Nil(t, err)
+			assert.Equal(t, "hello world", val)
+		}
+
+		os.Unsetenv(testEnvKey)
+	})
+
+	t.Run("should report an unknown complex config", func(t *testing.T) {
+		_, err := GetEnvironmentSetter(testEnvKey, map[string]interface{}{"from_nowhere": true}, SetterOptions{})
+
+		assert.ErrorContains(t, err, "unkown environment config")
+	})
+
+	t.Run("should report setter values with the wrong type instead of panicking", func(t *testing.T) {
+		for _, node := range []map[string]interface{}{
+			{CLI_ENVIRONMENT_SETTER_KEY: "yes"},
+			{CLI_ENVIRONMENT_SETTER_KEY: true, CLI_ENVIRONMENT_SECRET_SETTER_KEY: "no"},
+			{DRAGOMAN_ENV_KEY: 12345},
+			{SECRETS_MANAGER_ENV_ARN_KEY: 12345},
+			{SECRETS_MANAGER_ENV_ARN_KEY: "arn", SECRETS_MANAGER_ENV_JSON_KEY: true},
+			{VALUE_ENV_KEY: []interface{}{"a"}},
+			{VALUE_ENV_KEY: "a", SEPARATOR_ENV_KEY: 1},
+		} {
+			assert.NotPanics(t, func() {
+				_, err := GetEnvironmentSetter(testEnvKey, node, SetterOptions{})
+				assert.ErrorContains(t, err, testEnvKey)
+			})
+		}
+	})
+}
+
+func TestGetReferences(t *testing.T) {
+	t.Run("should find the references of the AWS setters", func(t *testing.T) {
+		assert.Equal(t, []string{"STAGE", "RELEASE"}, GetReferences(map[string]interface{}{
+			SECRETS_MANAGER_ENV_ARN_KEY:           "app/${STAGE}",
+			SECRETS_MANAGER_ENV_JSON_KEY:          "password",
+			SECRETS_MANAGER_ENV_VERSION_STAGE_KEY: "${RELEASE}",
+		}))
+		assert.Equal(t, []string{"STAGE"}, GetReferences(map[string]interface{}{SSM_ENV_KEY: "/app/${STAGE}/db_host"}))
+	})
+}
+
+func TestSetterType(t *testing.T) {
+	t.Run("should name the key that selects the setter", func(t *testing.T) {
+		assert.Equal(t, VALUE_ENV_KEY, SetterType("plain"))
+		assert.Equal(t, VALUE_ENV_KEY, SetterType(map[string]interface{}{VALUE_ENV_KEY: "a", MODE_ENV_KEY: "append"}))
+		assert.Equal(t, SECRETS_MANAGER_ENV_ARN_KEY, SetterType(map[string]interface{}{SECRETS_MANAGER_ENV_ARN_KEY: "arn", SECRETS_MANAGER_ENV_JSON_KEY: "key"}))
+		assert.Equal(t, TEMPLATE_ENV_KEY, SetterType(types.LayeredEnv{Config: map[string]interface{}{TEMPLATE_ENV_KEY: "{{ .A }}"}, Base: "b"}))
+	})
+}
+
+func TestIsSecret(t *testing.T) {
+	t.Run("should find the setters that set secrets", func(t *testing.T) {
+		assert.True(t, IsSecret(map[string]interface{}{SECRETS_MANAGER_ENV_ARN_KEY: "arn", SECRETS_MANAGER_ENV_JSON_KEY: "key"}))
+		assert.True(t, IsSecret(map[string]interface{}{DRAGOMAN_ENV_KEY: "[ENC,a]"}))
+		assert.True(t, IsSecret(map[string]interface{}{CLI_ENVIRONMENT_SETTER_KEY: true, CLI_ENVIRONMENT_SECRET_SETTER_KEY: true}))
+		assert.True(t, IsSecret(map[string]interface{}{COMMAND_ENV_KEY: []interface{}{"op", "read"}, COMMAND_SECRET_ENV_KEY: true}))
+		assert.True(t, IsSecret(map[string]interface{}{SSM_ENV_KEY: "/app/db_password", SSM_DECRYPT_ENV_KEY: true}))
+		assert.True(t, IsSecret(types.LayeredEnv{Config: "a", Base: map[string]interface{}{DRAGOMAN_ENV_KEY: "[ENC,a]"}}))
+		assert.False(t, IsSecret("plain"))
+		assert.False(t, IsSecret(map[string]interface{}{CLI_ENVIRONMENT_SETTER_KEY: true}))
+		assert.False(t, IsSecret(map[string]interface{}{COMMAND_ENV_KEY: []interface{}{"git", "rev-parse", "HEAD"}}))
+		assert.False(t, IsSecret(map[string]interface{}{SSM_ENV_KEY: "/app/db_host"}))
+	})
+}
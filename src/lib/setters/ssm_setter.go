@@ -0,0 +1,205 @@
+package setters
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/jeff-roche/biome/src/lib/interpolation"
+	"github.com/jeff-roche/biome/src/repos"
+)
+
+const SSM_ENV_KEY = "from_ssm"
+const SSM_DECRYPT_ENV_KEY = "ssm_decrypt"
+const SSM_VERSION_ENV_KEY = "ssm_version"
+const SSM_LABEL_ENV_KEY = "ssm_label"
+
+const SSM_SECURE_STRING = "SecureString"
+
+// SsmEnvironmentSetter will set an environment variable from a parameter
+// stored in AWS Systems Manager Parameter Store
+//
+// Parameters are loaded in batches, every from_ssm variable of the biome is
+// requested together the first time one of them is set
+type SsmEnvironmentSetter struct {
+	EnvKey   string // The environment variable key being set
+	Name     string // The name of the parameter, ${NAME} references are expanded
+	Decrypt  bool   // Decrypt a SecureString parameter
+	Selector string // The version (:3) or label (:prod) of the parameter to use
+	lookup   Lookup
+	batch    *SsmParameterBatch // The parameters being loaded with this one
+}
+
+// NewSsmEnvironmentSetter will generate a Parameter Store setter and add its parameter to the batch,
+// without a batch the parameter is loaded on its own
+func NewSsmEnvironmentSetter(key string, node map[string]interface{}, lookup Lookup, batch *SsmParameterBatch) (*SsmEnvironmentSetter, error) {
+	name, ok := node[SSM_ENV_KEY].(string)
+	if !ok || name == "" {
+		return nil, newSetterTypeError(key, SSM_ENV_KEY, "a parameter name")
+	}
+
+	setter := &SsmEnvironmentSetter{
+		EnvKey: key,
+		Name:   name,
+		lookup: lookup,
+		batch:  batch,
+	}
+
+	if setter.batch == nil {
+		setter.batch = NewSsmParameterBatch(nil)
+	}
+
+	if val, exists := node[SSM_DECRYPT_ENV_KEY]; exists {
+		if setter.Decrypt, ok = val.(bool); !ok {
+			return nil, newSetterTypeError(key, SSM_DECRYPT_ENV_KEY, "true or false")
+		}
+	}
+
+	// Version or Label
+	version, hasVersion := node[SSM_VERSION_ENV_KEY]
+	label, hasLabel := node[SSM_LABEL_ENV_KEY]
+
+	switch {
+	case hasVersion && hasLabel:
+		return nil, fmt.Errorf("'%s' for variable '%s' can't be used with '%s'", SSM_VERSION_ENV_KEY, key, SSM_LABEL_ENV_KEY)
+	case hasVersion:
+		num, ok := version.(int)
+		if !ok || num <= 0 {
+			return nil, newSetterTypeError(key, SSM_VERSION_ENV_KEY, "a version number")
+		}
+
+		setter.Selector = fmt.Sprintf(":%d", num)
+	case hasLabel:
+		str, ok := label.(string)
+		if !ok || str == "" {
+			return nil, newSetterTypeError(key, SSM_LABEL_ENV_KEY, "a label")
+		}
+
+		setter.Selector = ":" + str
+	}
+
+	// Names with references aren't known until the variables they reference are set
+	if lookup == nil || len(interpolation.References(name)) == 0 {
+		setter.batch.add(name+setter.Selector, setter.Decrypt)
+	}
+
+	return setter, nil
+}
+
+func (s SsmEnvironmentSetter) SetEnv() (string, error) {
+	name := s.Name
+	if s.lookup != nil {
+		var err error
+		if name, err = interpolation.Expand(name, s.lookup); err != nil {
+			return "", err
+		}
+	}
+
+	param, err := s.batch.get(name+s.Selector, s.Decrypt)
+	if err != nil {
+		return "", fmt.Errorf("unable to load parameter '%s': %v", name+s.Selector, err)
+	}
+
+	if param == nil {
+		return "", fmt.Errorf("the parameter '%s' does not exist", name+s.Selector)
+	}
+
+	if param.Type == SSM_SECURE_STRING && !s.Decrypt {
+		return "", fmt.Errorf("the parameter '%s' is a %s, set '%s: true' to decrypt it", name, SSM_SECURE_STRING, SSM_DECRYPT_ENV_KEY)
+	}
+
+	return param.Value, os.Setenv(s.EnvKey, param.Value)
+}
+
+// SsmParameterBatch collects the parameters of the from_ssm variables so they can be
+// loaded with as few GetParameters calls as possible
+type SsmParameterBatch struct {
+	mu      sync.Mutex
+	newRepo func() (repos.ParameterStoreIfc, error) // Builds the repo when the first parameter is loaded
+	repo    repos.ParameterStoreIfc
+	pending map[bool]map[string]bool                // The names still to load, by whether they are decrypted
+	loaded  map[bool]map[string]*repos.SsmParameter // The names already requested, nil if they don't exist
+}
+
+// NewSsmParameterBatch will start a batch of parameters loaded with the repo built by newRepo,
+// the repo is only built once a parameter is loaded so the biome's AWS session is configured first.
+// Without newRepo the default Parameter Store repo is used
+func NewSsmParameterBatch(newRepo func() (repos.ParameterStoreIfc, error)) *SsmParameterBatch {
+	if newRepo == nil {
+		newRepo = func() (repos.ParameterStoreIfc, error) {
+			return repos.NewParameterStoreRepo()
+		}
+	}
+
+	return &SsmParameterBatch{
+		newRepo: newRepo,
+		pending: map[bool]map[string]bool{true: {}, false: {}},
+		loaded:  map[bool]map[string]*repos.SsmParameter{true: {}, false: {}},
+	}
+}
+
+// add will queue the parameter to be loaded with the rest of the batch
+func (b *SsmParameterBatch) add(name string, decrypt bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.loaded[decrypt][name]; !exists {
+		b.pending[decrypt][name] = true
+	}
+}
+
+// get will return the parameter, loading it and every other pending parameter if it hasn't been loaded yet
+// A parameter that doesn't exist is nil
+func (b *SsmParameterBatch) get(name string, decrypt bool) (*repos.SsmParameter, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if param, exists := b.loaded[decrypt][name]; exists {
+		return param, nil
+	}
+
+	b.pending[decrypt][name] = true
+
+	if err := b.load(decrypt); err != nil {
+		return nil, err
+	}
+
+	return b.loaded[decrypt][name], nil
+}
+
+// load will request every pending parameter, the batch needs to be locked
+func (b *SsmParameterBatch) load(decrypt bool) error {
+	if b.repo == nil {
+		repo, err := b.newRepo()
+		if err != nil {
+			return fmt.Errorf("unable to initialize the parameter store repository: %v", err)
+		}
+
+		b.repo = repo
+	}
+
+	names := make([]string, 0, len(b.pending[decrypt]))
+	for name := range b.pending[decrypt] {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	params, err := b.repo.GetParameters(names, decrypt)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		var param *repos.SsmParameter
+		if val, exists := params[name]; exists {
+			param = &val
+		}
+
+		b.loaded[decrypt][name] = param
+		delete(b.pending[decrypt], name)
+	}
+
+	return nil
+}
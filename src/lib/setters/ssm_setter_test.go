@@ -0,0 +1,220 @@
+package setters
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jeff-roche/biome/src/repos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockParameterStoreRepo struct {
+	mock.Mock
+}
+
+func (r *mockParameterStoreRepo) GetParameters(names []string, decrypt bool) (map[string]repos.SsmParameter, error) {
+	args := r.Called(names, decrypt)
+	return args.Get(0).(map[string]repos.SsmParameter), args.Error(1)
+}
+
+func (r *mockParameterStoreRepo) GetParametersByPath(path string, decrypt bool) ([]repos.SsmParameter, error) {
+	args := r.Called(path, decrypt)
+	return args.Get(0).([]repos.SsmParameter), args.Error(1)
+}
+
+func TestSsmSetterBuilder(t *testing.T) {
+	envKey := "MY_ENV_VAR"
+
+	t.Run("should set all the keys specified", func(t *testing.T) {
+		// Act
+		setter, err := NewSsmEnvironmentSetter(envKey, map[string]interface{}{
+			SSM_ENV_KEY:         "/app/prod/db_password",
+			SSM_DECRYPT_ENV_KEY: true,
+			SSM_VERSION_ENV_KEY: 3,
+		}, nil, nil)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "/app/prod/db_password", setter.Name)
+		assert.True(t, setter.Decrypt)
+		assert.Equal(t, ":3", setter.Selector)
+	})
+
+	t.Run("should add the parameter to the batch it is given", func(t *testing.T) {
+		// Assemble
+		batch := NewSsmParameterBatch(nil)
+		other := NewSsmParameterBatch(nil)
+
+		// Act
+		setter, err := NewSsmEnvironmentSetter(envKey, map[string]interface{}{SSM_ENV_KEY: "/app/db_host"}, nil, batch)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Same(t, batch, setter.batch)
+		assert.Equal(t, map[string]bool{"/app/db_host": true}, batch.pending[false])
+		assert.Empty(t, other.pending[false])
+	})
+
+	t.Run("should select a label", func(t *testing.T) {
+		// Act
+		setter, err := NewSsmEnvironmentSetter(envKey, map[string]interface{}{SSM_ENV_KEY: "/app/db_host", SSM_LABEL_ENV_KEY: "stable"}, nil, nil)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.False(t, setter.Decrypt)
+		assert.Equal(t, ":stable", setter.Selector)
+	})
+
+	t.Run("should report invalid config", func(t *testing.T) {
+		// Act
+		_, bothErr := NewSsmEnvironmentSetter(envKey, map[string]interface{}{SSM_ENV_KEY: "/a", SSM_VERSION_ENV_KEY: 1, SSM_LABEL_ENV_KEY: "b"}, nil, nil)
+		_, versionErr := NewSsmEnvironmentSetter(envKey, map[string]interface{}{SSM_ENV_KEY: "/a", SSM_VERSION_ENV_KEY: "latest"}, nil, nil)
+		_, nameErr := NewSsmEnvironmentSetter(envKey, map[string]interface{}{SSM_ENV_KEY: ""}, nil, nil)
+
+		// Assert
+		assert.EqualError(t, bothErr, "'ssm_version' for variable 'MY_ENV_VAR' can't be used with 'ssm_label'")
+		assert.EqualError(t, versionErr, "'ssm_version' for variable 'MY_ENV_VAR' must be a version number")
+		assert.EqualError(t, nameErr, "'from_ssm' for variable 'MY_ENV_VAR' must be a parameter name")
+	})
+}
+
+func TestSsmSetter(t *testing.T) {
+	testEnv := "BIOME_TEST_SSM_ENV"
+
+	var batch *SsmParameterBatch
+
+	// Helper for building a setter in the batch of the mock repo
+	getTestSetter := func(t *testing.T, node map[string]interface{}, lookup Lookup) *SsmEnvironmentSetter {
+		setter, err := NewSsmEnvironmentSetter(testEnv, node, lookup, batch)
+		assert.Nil(t, err)
+
+		return setter
+	}
+
+	// Helper for starting a batch that loads from the mock repo
+	useMockRepo := func(t *testing.T) *mockParameterStoreRepo {
+		mockRepo := &mockParameterStoreRepo{}
+		batch = NewSsmParameterBatch(func() (repos.ParameterStoreIfc, error) {
+			return mockRepo, nil
+		})
+
+		t.Cleanup(func() {
+			os.Unsetenv(testEnv)
+		})
+
+		return mockRepo
+	}
+
+	t.Run("should load every parameter of the batch with one request", func(t *testing.T) {
+		// Assemble
+		mockRepo := useMockRepo(t)
+		mockRepo.On("GetParameters", []string{"/app/db_host", "/app/db_name:2", "/app/db_port"}, false).Return(map[string]repos.SsmParameter{
+			"/app/db_host":   {Name: "/app/db_host", Value: "db.internal", Type: "String"},
+			"/app/db_name:2": {Name: "/app/db_name", Value: "orders", Type: "String", Version: 2},
+			"/app/db_port":   {Name: "/app/db_port", Value: "5432", Type: "String"},
+		}, nil)
+
+		hostSetter := getTestSetter(t, map[string]interface{}{SSM_ENV_KEY: "/app/db_host"}, nil)
+		nameSetter := getTestSetter(t, map[string]interface{}{SSM_ENV_KEY: "/app/db_name", SSM_VERSION_ENV_KEY: 2}, nil)
+		portSetter := getTestSetter(t, map[string]interface{}{SSM_ENV_KEY: "/app/db_port"}, nil)
+
+		// Act
+		host, hostErr := hostSetter.SetEnv()
+		name, nameErr := nameSetter.SetEnv()
+		port, portErr := portSetter.SetEnv()
+
+		// Assert
+		assert.Nil(t, hostErr)
+		assert.Nil(t, nameErr)
+		assert.Nil(t, portErr)
+		assert.Equal(t, "db.internal", host)
+		assert.Equal(t, "orders", name)
+		assert.Equal(t, "5432", port)
+		assert.Equal(t, "5432", os.Getenv(testEnv))
+		mockRepo.AssertNumberOfCalls(t, "GetParameters", 1)
+	})
+
+	t.Run("should load decrypted parameters separately", func(t *testing.T) {
+		// Assemble
+		mockRepo := useMockRepo(t)
+		mockRepo.On("GetParameters", []string{"/app/db_host"}, false).Return(map[string]repos.SsmParameter{
+			"/app/db_host": {Name: "/app/db_host", Value: "db.internal", Type: "String"},
+		}, nil)
+		mockRepo.On("GetParameters", []string{"/app/db_password"}, true).Return(map[string]repos.SsmParameter{
+			"/app/db_password": {Name: "/app/db_password", Value: "hunter2", Type: SSM_SECURE_STRING},
+		}, nil)
+
+		getTestSetter(t, map[string]interface{}{SSM_ENV_KEY: "/app/db_host"}, nil)
+		setter := getTestSetter(t, map[string]interface{}{SSM_ENV_KEY: "/app/db_password", SSM_DECRYPT_ENV_KEY: true}, nil)
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "hunter2", val)
+		mockRepo.AssertNotCalled(t, "GetParameters", []string{"/app/db_host"}, false)
+	})
+
+	t.Run("should expand references in the name when it is set", func(t *testing.T) {
+		// Assemble
+		mockRepo := useMockRepo(t)
+		mockRepo.On("GetParameters", []string{"/app/staging/db_host"}, false).Return(map[string]repos.SsmParameter{
+			"/app/staging/db_host": {Name: "/app/staging/db_host", Value: "staging.internal", Type: "String"},
+		}, nil)
+
+		lookup := func(name string) (string, bool) { return "staging", name == "STAGE" }
+		setter := getTestSetter(t, map[string]interface{}{SSM_ENV_KEY: "/app/${STAGE}/db_host"}, lookup)
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "staging.internal", val)
+	})
+
+	t.Run("should report parameters that don't exist", func(t *testing.T) {
+		// Assemble
+		mockRepo := useMockRepo(t)
+		mockRepo.On("GetParameters", []string{"/app/missing:stable"}, false).Return(map[string]repos.SsmParameter{}, nil)
+		setter := getTestSetter(t, map[string]interface{}{SSM_ENV_KEY: "/app/missing", SSM_LABEL_ENV_KEY: "stable"}, nil)
+
+		// Act
+		_, err := setter.SetEnv()
+
+		// Assert
+		assert.EqualError(t, err, "the parameter '/app/missing:stable' does not exist")
+	})
+
+	t.Run("should report a SecureString that isn't decrypted", func(t *testing.T) {
+		// Assemble
+		mockRepo := useMockRepo(t)
+		mockRepo.On("GetParameters", []string{"/app/db_password"}, false).Return(map[string]repos.SsmParameter{
+			"/app/db_password": {Name: "/app/db_password", Value: "AQICAH...", Type: SSM_SECURE_STRING},
+		}, nil)
+		setter := getTestSetter(t, map[string]interface{}{SSM_ENV_KEY: "/app/db_password"}, nil)
+
+		// Act
+		_, err := setter.SetEnv()
+
+		// Assert
+		assert.EqualError(t, err, "the parameter '/app/db_password' is a SecureString, set 'ssm_decrypt: true' to decrypt it")
+	})
+
+	t.Run("should report an error getting the parameters", func(t *testing.T) {
+		// Assemble
+		mockRepo := useMockRepo(t)
+		mockRepo.On("GetParameters", []string{"/app/db_host"}, false).Return(map[string]repos.SsmParameter{}, fmt.Errorf("access denied"))
+		setter := getTestSetter(t, map[string]interface{}{SSM_ENV_KEY: "/app/db_host"}, nil)
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Equal(t, "", val)
+		assert.EqualError(t, err, "unable to load parameter '/app/db_host': access denied")
+	})
+}
@@ -0,0 +1,207 @@
+package setters
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/jeff-roche/biome/src/repos"
+)
+
+const TEMPLATE_ENV_KEY = "from_template"
+
+// TemplateEnvironmentSetter will set an environment variable by rendering a go text/template
+type TemplateEnvironmentSetter struct {
+	EnvKey   string               // The environment variable to be set
+	Template string               // The template to render
+	Biome    string               // The name of the active biome, available as {{ .Biome }}
+	Dir      string               // The directory relative paths given to file are resolved from
+	identity repos.AwsIdentityIfc // Looks up the AWS account when the template uses it
+}
+
+// NewTemplateEnvironmentSetter is the builder function for TemplateEnvironmentSetter
+func NewTemplateEnvironmentSetter(key string, tmpl string, biome string, dir string) *TemplateEnvironmentSetter {
+	return &TemplateEnvironmentSetter{
+		EnvKey:   key,
+		Template: tmpl,
+		Biome:    biome,
+		Dir:      dir,
+		identity: repos.NewAwsStsRepository(),
+	}
+}
+
+func (s TemplateEnvironmentSetter) SetEnv() (string, error) {
+	tmpl, err := template.New(s.EnvKey).Option("missingkey=error").Funcs(templateFuncs(s.Dir)).Parse(s.Template)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse the template for '%s': %v", s.EnvKey, err)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, templateData{Biome: s.Biome, AWS: templateAWS{identity: s.identity}}); err != nil {
+		return "", fmt.Errorf("unable to render the template for '%s': %v", s.EnvKey, err)
+	}
+
+	val := out.String()
+
+	return val, os.Setenv(s.EnvKey, val)
+}
+
+// templateData is the data available to templates (ex: {{ .Biome }} or {{ .AWS.Region }})
+type templateData struct {
+	Biome string
+	AWS   templateAWS
+}
+
+// templateAWS exposes the AWS session to templates, the account is only looked up if it is used
+type templateAWS struct {
+	identity repos.AwsIdentityIfc
+}
+
+func (a templateAWS) Region() (string, error) {
+	return a.identity.GetRegion()
+}
+
+func (a templateAWS) Account() (string, error) {
+	return a.identity.GetAccountID()
+}
+
+// templateFuncs are the functions available to templates, relative paths given to file are resolved from dir
+func templateFuncs(dir string) template.FuncMap {
+	return template.FuncMap{
+		"env": os.Getenv,
+		"file": func(fpath string) (string, error) {
+			contents, err := os.ReadFile(resolveFilePath(fpath, dir))
+			return string(contents), err
+		},
+		"base64enc": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+		"base64dec": func(s string) (string, error) {
+			dec, err := base64.StdEncoding.DecodeString(s)
+			return string(dec), err
+		},
+		"trim":  strings.TrimSpace,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"default": func(def string, val string) string {
+			if val == "" {
+				return def
+			}
+
+			return val
+		},
+		"required": func(msg string, val string) (string, error) {
+			if val == "" {
+				return "", fmt.Errorf("%s", msg)
+			}
+
+			return val, nil
+		},
+		"now": time.Now,
+		"date": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+		"sha256": func(s string) string {
+			sum := sha256.Sum256([]byte(s))
+			return hex.EncodeToString(sum[:])
+		},
+		"json": templateJSONField,
+	}
+}
+
+// templateJSONField will pull the field at the dot separated path out of the JSON document
+// Strings are returned as is and any other value is returned as JSON
+func templateJSONField(path string, doc string) (string, error) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(doc), &data); err != nil {
+		return "", fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := data.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("unable to find '%s' in the JSON, '%s' is not an object", path, key)
+		}
+
+		if data, ok = obj[key]; !ok {
+			return "", fmt.Errorf("the JSON does not contain '%s'", path)
+		}
+	}
+
+	if str, ok := data.(string); ok {
+		return str, nil
+	}
+
+	out, err := json.Marshal(data)
+	return string(out), err
+}
+
+// TemplateReferences will return the names of the variables a template reads with the env function
+func TemplateReferences(tmpl string) []string {
+	trees, err := parse.Parse("refs", tmpl, "", "", templateFuncs(""))
+	if err != nil {
+		return nil
+	}
+
+	var refs []string
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			if len(n.Args) >= 2 {
+				if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "env" {
+					if str, ok := n.Args[1].(*parse.StringNode); ok {
+						refs = append(refs, str.Text)
+					}
+				}
+			}
+
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		}
+	}
+
+	for _, tree := range trees {
+		walk(tree.Root)
+	}
+
+	return refs
+}
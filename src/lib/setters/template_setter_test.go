@@ -0,0 +1,174 @@
+package setters
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAwsIdentityRepo struct {
+	mock.Mock
+}
+
+func (r *mockAwsIdentityRepo) GetAccountID() (string, error) {
+	args := r.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (r *mockAwsIdentityRepo) GetRegion() (string, error) {
+	args := r.Called()
+	return args.String(0), args.Error(1)
+}
+
+func TestTemplateSetter(t *testing.T) {
+	testEnv := "BIOME_TEST_TEMPLATE_ENV"
+
+	// Helper test builder function for setup
+	getTestSetter := func(tmpl string) *TemplateEnvironmentSetter {
+		return &TemplateEnvironmentSetter{
+			EnvKey:   testEnv,
+			Template: tmpl,
+			identity: &mockAwsIdentityRepo{},
+		}
+	}
+
+	t.Cleanup(func() {
+		os.Unsetenv(testEnv)
+	})
+
+	t.Run("should render the template and set the env", func(t *testing.T) {
+		// Assemble
+		os.Setenv("BIOME_TEST_TEMPLATE_USER", "Admin")
+		t.Cleanup(func() {
+			os.Unsetenv("BIOME_TEST_TEMPLATE_USER")
+		})
+
+		setter := getTestSetter(`{{ env "BIOME_TEST_TEMPLATE_USER" | lower }}@{{ env "BIOME_TEST_NOT_SET" | default "localhost" }}`)
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "admin@localhost", val)
+		assert.Equal(t, "admin@localhost", os.Getenv(testEnv))
+	})
+
+	t.Run("should support the curated functions", func(t *testing.T) {
+		// Assemble
+		fpath := filepath.Join(t.TempDir(), "token")
+		assert.Nil(t, os.WriteFile(fpath, []byte("  my-token\n"), 0600))
+
+		for tmpl, expected := range map[string]string{
+			fmt.Sprintf(`{{ file "%s" | trim }}`, fpath):                 "my-token",
+			`{{ "hello" | base64enc }}`:                                  "aGVsbG8=",
+			`{{ "aGVsbG8=" | base64dec | upper }}`:                       "HELLO",
+			`{{ "hello" | sha256 }}`:                                     "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+			`{{ json "db.host" "{\"db\": {\"host\": \"localhost\"}}" }}`: "localhost",
+			`{{ json "db" "{\"db\": {\"port\": 5432}}" }}`:               `{"port":5432}`,
+			`{{ now | date "2006" | len }}`:                              "4",
+			`{{ "set" | required "must be set" }}`:                       "set",
+		} {
+			setter := getTestSetter(tmpl)
+
+			// Act
+			val, err := setter.SetEnv()
+
+			// Assert
+			assert.Nil(t, err, tmpl)
+			assert.Equal(t, expected, val, tmpl)
+		}
+	})
+
+	t.Run("should expose the biome name and AWS session", func(t *testing.T) {
+		// Assemble
+		identity := &mockAwsIdentityRepo{}
+		identity.On("GetAccountID").Return("123456789012", nil)
+		identity.On("GetRegion").Return("us-west-2", nil)
+		setter := getTestSetter("{{ .Biome }}/{{ .AWS.Region }}/{{ .AWS.Account }}")
+		setter.Biome = "my-biome"
+		setter.identity = identity
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "my-biome/us-west-2/123456789012", val)
+	})
+
+	t.Run("should not look up the AWS account unless it is used", func(t *testing.T) {
+		// Assemble
+		identity := &mockAwsIdentityRepo{}
+		identity.On("GetRegion").Return("us-west-2", nil)
+		setter := getTestSetter("{{ .AWS.Region }}")
+		setter.identity = identity
+
+		// Act
+		_, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		identity.AssertNotCalled(t, "GetAccountID")
+	})
+
+	t.Run("should read files relative to the config file", func(t *testing.T) {
+		// Assemble
+		configDir, cwd := t.TempDir(), t.TempDir()
+		assert.Nil(t, os.WriteFile(filepath.Join(configDir, "token"), []byte("config-token"), 0600))
+		assert.Nil(t, os.WriteFile(filepath.Join(cwd, "token"), []byte("cwd-token"), 0600))
+
+		wd, err := os.Getwd()
+		assert.Nil(t, err)
+		assert.Nil(t, os.Chdir(cwd))
+		t.Cleanup(func() {
+			os.Chdir(wd)
+		})
+
+		setter := NewTemplateEnvironmentSetter(testEnv, `{{ file "token" }}`, "", configDir)
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "config-token", val)
+	})
+
+	t.Run("should name the variable and the failing function in errors", func(t *testing.T) {
+		// Assemble
+		setter := getTestSetter(`{{ env "BIOME_TEST_NOT_SET" | required "BIOME_TEST_NOT_SET must be set" }}`)
+
+		// Act
+		val, err := setter.SetEnv()
+
+		// Assert
+		assert.Equal(t, "", val)
+		assert.ErrorContains(t, err, testEnv)
+		assert.ErrorContains(t, err, "required")
+		assert.ErrorContains(t, err, "BIOME_TEST_NOT_SET must be set")
+	})
+
+	t.Run("should report template syntax errors", func(t *testing.T) {
+		// Assemble
+		setter := getTestSetter(`{{ env "A" `)
+
+		// Act
+		_, err := setter.SetEnv()
+
+		// Assert
+		assert.ErrorContains(t, err, "unable to parse the template for '"+testEnv+"'")
+	})
+}
+
+func TestTemplateReferences(t *testing.T) {
+	t.Run("should find the variables read with env", func(t *testing.T) {
+		refs := TemplateReferences(`{{ env "A" }}{{ if env "B" }}{{ (env "C") | lower }}{{ end }}{{ .Biome }}`)
+
+		assert.Equal(t, []string{"A", "B", "C"}, refs)
+	})
+}
@@ -1,69 +1,178 @@
 package types
 
 import (
-	"fmt"
+	"sort"
 )
 
+// CONFIG_VERSION is the version of the config format this binary reads and writes
+// Documents without a version are version 1, the format before versions were added
+const CONFIG_VERSION = 2
+
 type Biome struct {
 	Config     *BiomeConfig
 	SourceFile string
 }
 
-type BiomeConfig struct {
-	Name            string
-	AwsProfile      string                 `yaml:"aws_profile"`
-	Commands        []string               `yaml:"commands"`
-	ExternalEnvFile string                 `yaml:"load_env"`
-	Environment     map[string]interface{} `yaml:"environment"`
-	Inheritance     string                 `yaml:"inherit_from"`
+// EnvSource is where the config of an environment variable was declared
+type EnvSource struct {
+	Biome  string // The biome that declared the variable
+	File   string
+	Line   int
+	Dotenv bool   // Loaded from the biome's load_env file
+	Local  bool   // Set by a local override file (.biome.local.yaml)
+	From   string // The source that expanded into the variable (ex: ssm_path /app/prod/)
+	Secret bool   // The source loaded a secret (ex: from Secrets Manager)
 }
 
-func (bc *BiomeConfig) Inherit(genepool map[string]*BiomeConfig) error {
+type BiomeConfig struct {
+	Version     int                    `yaml:"version" desc:"The version of the config format the document is written in"`
+	Name        string                 `desc:"The name used to select the biome"`
+	Description string                 `yaml:"description" desc:"What the biome is for, shown by biome list"`
+	Tags        StringList             `yaml:"tags" desc:"Tags used to group and filter biomes with biome list --tag"`
+	AwsProfile  string                 `yaml:"aws_profile" desc:"The AWS profile to configure the session and AWS environment variables from"`
+	Commands    []string               `yaml:"commands" desc:"Commands to run after the environment is configured"`
+	EnvFiles    EnvFileList            `yaml:"load_env" desc:"Dotenv, JSON or YAML files to load additional environment variables from, later files take precedence"`
+	Sources     []SourceConfig         `yaml:"sources" desc:"Secrets, Parameter Store paths or files that expand into many environment variables, later sources take precedence"`
+	Environment map[string]interface{} `yaml:"environment" desc:"The environment variables to set"`
+	Inheritance StringList             `yaml:"inherit_from" desc:"The biome(s) to inherit configuration from"`
+	Merge       MergeConfig            `yaml:"merge" desc:"How the inherited configuration is merged in"`
+	Include     StringList             `yaml:"include" desc:"Other config files to load biomes from, relative to this file"`
+	Unset       StringList             `yaml:"unset" desc:"Host environment variables to remove before the biome is activated, glob patterns (AWS_*) are allowed"`
+	Isolate     *bool                  `yaml:"isolate" desc:"Start from a clean environment that only keeps the allowed host variables, the nearest biome that sets it wins"`
+	AllowEnv    StringList             `yaml:"allow_env" desc:"Host environment variables to keep in addition to the defaults when isolated, glob patterns are allowed"`
+	Params      map[string]ParamConfig `yaml:"params" desc:"Values supplied when the biome is activated (biome run -p name=value), referenced with ${name}"`
+	EnvOrder    []string               `yaml:"-"` // The declared order of the Environment keys
+	ParamOrder  []string               `yaml:"-"` // The declared order of the Params keys
+	EnvSources  map[string]EnvSource   `yaml:"-"` // Where each of the Environment keys was declared
+	Parents     []string               `yaml:"-"` // The inherited biomes in precedence order, set by Inherit
+	ProfileFile string                 `yaml:"-"` // The local override file that replaced the aws_profile
+	SourceFile  string                 `yaml:"-"` // The config file the biome was loaded from
+	SourceLine  int                    `yaml:"-"` // The line in the config file the biome starts on
+}
 
-	parents := make([]string, 0, len(genepool))
-	unique := make(map[string]bool, len(genepool))
-	inherits_from := bc.Inheritance
+// EnvKeys will return the Environment keys in the order they should be resolved
+//
+// Keys are returned in their declared order, inherited keys come after the biome's own keys
+// (nearest parent first) and any keys without a declared order are last in alphabetical order
+func (bc *BiomeConfig) EnvKeys() []string {
+	keys := make([]string, 0, len(bc.Environment))
+	seen := make(map[string]bool, len(bc.Environment))
 
-	for inherits_from != "" {
-		// Does it exist?
-		if _, exists := genepool[inherits_from]; !exists {
-			return fmt.Errorf("can not find inherited biome %s", inherits_from)
+	for _, key := range bc.EnvOrder {
+		if _, exists := bc.Environment[key]; exists && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
 		}
+	}
 
-		// Ciruclar inheritance check
-		if _, exists := unique[inherits_from]; exists {
-			return fmt.Errorf("circular biome inheritance found, %s inherited cyclicly", inherits_from)
+	var unordered []string
+	for key := range bc.Environment {
+		if !seen[key] {
+			unordered = append(unordered, key)
 		}
+	}
+
+	sort.Strings(unordered)
 
-		unique[inherits_from] = true
+	return append(keys, unordered...)
+}
 
-		parents = append(parents, inherits_from)
-		inherits_from = genepool[inherits_from].Inheritance
+// AddEnv will add the environment variable to the end of the resolution order
+// or replace its value if it already exists
+func (bc *BiomeConfig) AddEnv(key string, val interface{}) {
+	if bc.Environment == nil {
+		bc.Environment = make(map[string]interface{})
 	}
 
-	// Now do the inheritance
-	for _, i := range parents {
-		biome := genepool[i]
-		// AWS Profile (if one hasn't been set)
-		if bc.AwsProfile == "" {
-			bc.AwsProfile = biome.AwsProfile
-		}
+	if _, exists := bc.Environment[key]; !exists {
+		bc.EnvOrder = append(bc.EnvOrder, key)
+	}
+
+	bc.Environment[key] = val
+}
+
+// AddEnvFrom will add the environment variable like AddEnv and record where it was declared
+func (bc *BiomeConfig) AddEnvFrom(key string, val interface{}, source EnvSource) {
+	bc.AddEnv(key, val)
+
+	if bc.EnvSources == nil {
+		bc.EnvSources = make(map[string]EnvSource)
+	}
+
+	bc.EnvSources[key] = source
+}
 
-		// Load in any external env files (if one isn't specified yet)
-		if bc.ExternalEnvFile == "" {
-			bc.ExternalEnvFile = biome.ExternalEnvFile
+// EnvSource will return where the environment variable was declared, defaulting to the biome itself
+func (bc *BiomeConfig) EnvSource(key string) EnvSource {
+	if source, exists := bc.EnvSources[key]; exists {
+		return source
+	}
+
+	return EnvSource{Biome: bc.Name, File: bc.SourceFile}
+}
+
+// IsIsolated will determine if the biome starts from a clean environment, biomes aren't isolated unless they set isolate
+func (bc *BiomeConfig) IsIsolated() bool {
+	return bc.Isolate != nil && *bc.Isolate
+}
+
+// ApplyLocal will apply a biome from a local override file on top of this biome
+//
+// A local aws_profile replaces the biome's and local environment variables are added to the biome
+// or override its config, variables that prepend or append build on the biome's value
+func (bc *BiomeConfig) ApplyLocal(local *BiomeConfig) {
+	if local.AwsProfile != "" {
+		bc.AwsProfile = local.AwsProfile
+		bc.ProfileFile = local.SourceFile
+	}
+
+	for _, env := range local.EnvKeys() {
+		source := local.EnvSource(env)
+		source.Biome = bc.Name
+		source.Local = true
+
+		inherited, exists := bc.Environment[env]
+		bc.AddEnvFrom(env, layerEnv(local.Environment[env], inherited, bc.EnvSource(env), exists), source)
+	}
+}
+
+// Copy will make a copy of the biome that can be changed (ex: by inheritance) without changing the original
+func (bc *BiomeConfig) Copy() *BiomeConfig {
+	biome := *bc
+
+	biome.Commands = append([]string(nil), bc.Commands...)
+	biome.Inheritance = append(StringList(nil), bc.Inheritance...)
+	biome.Include = append(StringList(nil), bc.Include...)
+	biome.EnvFiles = append(EnvFileList(nil), bc.EnvFiles...)
+	biome.Sources = append([]SourceConfig(nil), bc.Sources...)
+	biome.Tags = append(StringList(nil), bc.Tags...)
+	biome.Unset = append(StringList(nil), bc.Unset...)
+	biome.AllowEnv = append(StringList(nil), bc.AllowEnv...)
+	biome.EnvOrder = append([]string(nil), bc.EnvOrder...)
+	biome.ParamOrder = append([]string(nil), bc.ParamOrder...)
+	biome.Parents = append([]string(nil), bc.Parents...)
+	biome.Merge.RemoveEnv = append([]string(nil), bc.Merge.RemoveEnv...)
+
+	if bc.Environment != nil {
+		biome.Environment = make(map[string]interface{}, len(bc.Environment))
+		for key, val := range bc.Environment {
+			biome.Environment[key] = val
 		}
+	}
 
-		// Envs (only if they don't already exist)
-		for env, val := range biome.Environment {
-			if _, exists := bc.Environment[env]; !exists {
-				bc.Environment[env] = val
-			}
+	if bc.EnvSources != nil {
+		biome.EnvSources = make(map[string]EnvSource, len(bc.EnvSources))
+		for key, val := range bc.EnvSources {
+			biome.EnvSources[key] = val
 		}
+	}
 
-		// Commands (prepend to the commands array)
-		bc.Commands = append(biome.Commands, bc.Commands...)
+	if bc.Params != nil {
+		biome.Params = make(map[string]ParamConfig, len(bc.Params))
+		for key, val := range bc.Params {
+			biome.Params[key] = val
+		}
 	}
 
-	return nil
+	return &biome
 }
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBiomeConfig(t *testing.T) {
+	t.Run("EnvKeys", func(t *testing.T) {
+		t.Run("should put undeclared keys last in alphabetical order", func(t *testing.T) {
+			// Assemble
+			bc := BiomeConfig{
+				Environment: map[string]interface{}{"C": "c", "B": "b", "A": "a", "D": "d"},
+				EnvOrder:    []string{"C", "A", "MISSING"},
+			}
+
+			// Act
+			keys := bc.EnvKeys()
+
+			// Assert
+			assert.Equal(t, []string{"C", "A", "B", "D"}, keys)
+		})
+	})
+
+	t.Run("ApplyLocal", func(t *testing.T) {
+		t.Run("should override the aws_profile and layer the local variables on the biome's", func(t *testing.T) {
+			// Assemble
+			bc := BiomeConfig{Name: "project", AwsProfile: "team", SourceFile: ".biome.yaml"}
+			bc.AddEnv("PATH", "/usr/bin")
+			bc.AddEnv("DEBUG", "false")
+
+			local := BiomeConfig{
+				Name:       "project",
+				AwsProfile: "personal",
+				SourceFile: ".biome.local.yaml",
+				Environment: map[string]interface{}{
+					"DEBUG": "true",
+					"PATH":  map[string]interface{}{"value": "./bin", "mode": "prepend"},
+				},
+				EnvOrder: []string{"DEBUG", "PATH"},
+			}
+
+			// Act
+			bc.ApplyLocal(&local)
+
+			// Assert
+			assert.Equal(t, "personal", bc.AwsProfile)
+			assert.Equal(t, ".biome.local.yaml", bc.ProfileFile)
+			assert.Equal(t, []string{"PATH", "DEBUG"}, bc.EnvKeys())
+			assert.Equal(t, "true", bc.Environment["DEBUG"])
+			assert.Equal(t, LayeredEnv{Config: local.Environment["PATH"], Base: "/usr/bin", BaseDir: "."}, bc.Environment["PATH"])
+			assert.Equal(t, EnvSource{Biome: "project", File: ".biome.local.yaml", Local: true}, bc.EnvSource("DEBUG"))
+		})
+	})
+}
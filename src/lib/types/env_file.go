@@ -0,0 +1,78 @@
+package types
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jeff-roche/biome/src/lib/fileio"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvFile is a file of environment variables loaded with load_env
+type EnvFile struct {
+	Path     string `yaml:"path" desc:"The file to load, relative to the config file"`
+	Optional bool   `yaml:"optional" desc:"Skip the file when it doesn't exist (ex: .env.local)"`
+	Format   string `yaml:"format" enum:"dotenv,json,yaml" desc:"The format of the file, by default .json and .yaml/.yml files are JSON and YAML and any other file is a dotenv file"`
+	Dir      string `yaml:"-"` // The directory of the config file the path is relative to
+}
+
+// ResolvedPath will return the path of the file, relative paths are resolved from the directory of the config file
+func (f EnvFile) ResolvedPath() string {
+	path := fileio.ExpandHome(f.Path)
+
+	if f.Dir == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(f.Dir, path)
+}
+
+func (f *EnvFile) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*f = EnvFile{Path: node.Value}
+		return nil
+	}
+
+	// Decode into a type without this method to use the default decoding
+	type envFile EnvFile
+
+	var file envFile
+	if err := node.Decode(&file); err != nil {
+		return err
+	}
+
+	if file.Path == "" {
+		return fmt.Errorf("line %d: an env file needs a 'path'", node.Line)
+	}
+
+	*f = EnvFile(file)
+
+	return nil
+}
+
+// EnvFileList is a list of env files that can be written in YAML as a single path or a list of
+// paths and env files. Files later in the list take precedence over the files before them
+type EnvFileList []EnvFile
+
+func (l *EnvFileList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			*l = nil
+			return nil
+		}
+
+		*l = EnvFileList{{Path: node.Value}}
+	case yaml.SequenceNode:
+		var files []EnvFile
+		if err := node.Decode(&files); err != nil {
+			return err
+		}
+
+		*l = files
+	default:
+		return fmt.Errorf("line %d: expected a path or a list of env files", node.Line)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package types
+
+import "fmt"
+
+const (
+	ENV_DESCRIPTION_FIELD = "description"
+	ENV_EXAMPLE_FIELD     = "example"
+	ENV_REQUIRED_FIELD    = "required"
+	ENV_DEPRECATED_FIELD  = "deprecated"
+)
+
+var EnvMetadataFields = []string{ENV_DESCRIPTION_FIELD, ENV_EXAMPLE_FIELD, ENV_REQUIRED_FIELD, ENV_DEPRECATED_FIELD}
+
+// EnvMetadata documents an environment variable, it doesn't change the value that is set
+type EnvMetadata struct {
+	Description        string
+	Example            string
+	Required           bool // The value can't be empty when the biome is activated
+	Deprecated         bool
+	DeprecationMessage string // Why the variable is deprecated or what to use instead
+}
+
+// GetEnvMetadata will return the metadata of an environment config
+//
+// Deprecated is either true or a message explaining the deprecation. A layered config
+// keeps the inherited metadata it doesn't set itself
+func GetEnvMetadata(config interface{}) EnvMetadata {
+	var meta EnvMetadata
+
+	switch val := config.(type) {
+	case map[string]interface{}:
+		meta.Description, _ = val[ENV_DESCRIPTION_FIELD].(string)
+		meta.Required, _ = val[ENV_REQUIRED_FIELD].(bool)
+
+		if example, exists := val[ENV_EXAMPLE_FIELD]; exists && example != nil {
+			meta.Example = fmt.Sprint(example)
+		}
+
+		switch deprecated := val[ENV_DEPRECATED_FIELD].(type) {
+		case bool:
+			meta.Deprecated = deprecated
+		case string:
+			meta.Deprecated = true
+			meta.DeprecationMessage = deprecated
+		}
+	case LayeredEnv:
+		meta = GetEnvMetadata(val.Config)
+		base := GetEnvMetadata(val.Base)
+
+		if meta.Description == "" {
+			meta.Description = base.Description
+		}
+
+		if meta.Example == "" {
+			meta.Example = base.Example
+		}
+
+		if !meta.Deprecated {
+			meta.Deprecated = base.Deprecated
+			meta.DeprecationMessage = base.DeprecationMessage
+		}
+
+		meta.Required = meta.Required || base.Required
+	}
+
+	return meta
+}
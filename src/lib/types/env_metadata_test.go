@@ -0,0 +1,54 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEnvMetadata(t *testing.T) {
+	t.Run("should read the metadata keys of a setter", func(t *testing.T) {
+		// Assemble
+		config := map[string]interface{}{
+			"value":       "x",
+			"description": "What it is",
+			"example":     8080,
+			"required":    true,
+			"deprecated":  "use Y instead",
+		}
+
+		// Act
+		meta := GetEnvMetadata(config)
+
+		// Assert
+		assert.Equal(t, EnvMetadata{
+			Description:        "What it is",
+			Example:            "8080",
+			Required:           true,
+			Deprecated:         true,
+			DeprecationMessage: "use Y instead",
+		}, meta)
+	})
+
+	t.Run("should have no metadata for a basic value", func(t *testing.T) {
+		// Act
+		meta := GetEnvMetadata("x")
+
+		// Assert
+		assert.Equal(t, EnvMetadata{}, meta)
+	})
+
+	t.Run("should keep the inherited metadata a layered config doesn't set", func(t *testing.T) {
+		// Assemble
+		config := LayeredEnv{
+			Config: map[string]interface{}{"value": "./bin", "mode": "prepend", "example": "./tools"},
+			Base:   map[string]interface{}{"value": "/usr/bin", "description": "The search path", "example": "/bin", "deprecated": true},
+		}
+
+		// Act
+		meta := GetEnvMetadata(config)
+
+		// Assert
+		assert.Equal(t, EnvMetadata{Description: "The search path", Example: "./tools", Deprecated: true}, meta)
+	})
+}
@@ -0,0 +1,54 @@
+package types
+
+import "path/filepath"
+
+const ENV_MODE_FIELD = "mode"
+
+const (
+	ENV_MODE_OVERRIDE = "override" // Replace the current value (default)
+	ENV_MODE_DEFAULT  = "default"  // Only set the value if the variable isn't already set
+	ENV_MODE_PREPEND  = "prepend"  // Add the value before the current value
+	ENV_MODE_APPEND   = "append"   // Add the value after the current value
+)
+
+var EnvModes = []string{ENV_MODE_OVERRIDE, ENV_MODE_DEFAULT, ENV_MODE_PREPEND, ENV_MODE_APPEND}
+
+// LayeredEnv is an environment config that builds on top of the config it inherited,
+// such as a biome prepending to a PATH its parent also prepends to
+type LayeredEnv struct {
+	Config  interface{} // The biome's own config
+	Base    interface{} // The inherited config, which is set first
+	BaseDir string      // The directory of the config file the inherited config was declared in
+}
+
+// EnvMode will return the mode of an environment config, or an empty string if it doesn't have one
+func EnvMode(config interface{}) string {
+	switch val := config.(type) {
+	case map[string]interface{}:
+		mode, _ := val[ENV_MODE_FIELD].(string)
+		return mode
+	case LayeredEnv:
+		return EnvMode(val.Config)
+	}
+
+	return ""
+}
+
+// layerEnv will layer the config on top of the inherited config when its mode adds to the current value
+func layerEnv(config interface{}, inherited interface{}, inheritedSource EnvSource, exists bool) interface{} {
+	if !exists {
+		return config
+	}
+
+	switch EnvMode(config) {
+	case ENV_MODE_PREPEND, ENV_MODE_APPEND:
+		layered := LayeredEnv{Config: config, Base: inherited}
+		if inheritedSource.File != "" {
+			layered.BaseDir = filepath.Dir(inheritedSource.File)
+		}
+
+		return layered
+	}
+
+	return config
+}
@@ -0,0 +1,306 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	MERGE_PREPEND = "prepend" // Inherited commands run before the biome's own commands
+	MERGE_APPEND  = "append"  // Inherited commands run after the biome's own commands
+	MERGE_REPLACE = "replace" // Inherited values are dropped
+	MERGE_MERGE   = "merge"   // Inherited envs are kept unless the biome overrides them
+)
+
+// MergeConfig controls how a biome merges in the configuration it inherits
+type MergeConfig struct {
+	Commands    string   `yaml:"commands" enum:"prepend,append,replace" desc:"Whether the inherited commands are prepended (default), appended or replaced"`
+	Environment string   `yaml:"environment" enum:"merge,replace" desc:"Whether the inherited environment variables are merged (default) or replaced"`
+	RemoveEnv   []string `yaml:"remove_env" desc:"Inherited environment variables to remove"`
+}
+
+// Inherit will merge the configuration of every biome in the inheritance tree into this biome
+//
+// The tree is linearized with C3 (the same as Python's method resolution order) so each biome
+// shows up once, after every biome that inherits from it and in the order it was listed in inherit_from.
+// The configuration is then merged from the most basic biome up to this one, with each biome applying
+// its own merge settings to everything that comes after it in the linearization
+func (bc *BiomeConfig) Inherit(genepool map[string]*BiomeConfig) error {
+	lineage, err := linearize(bc.Name, bc, genepool, nil, make(map[string][]string))
+	if err != nil {
+		return err
+	}
+
+	biomes := make([]*BiomeConfig, len(lineage))
+	biomes[0] = bc
+	for i := 1; i < len(lineage); i++ {
+		biomes[i] = genepool[lineage[i]]
+	}
+
+	merged := &BiomeConfig{
+		Environment: make(map[string]interface{}),
+		EnvSources:  make(map[string]EnvSource),
+		Params:      make(map[string]ParamConfig),
+	}
+
+	for i := len(biomes) - 1; i >= 0; i-- {
+		if err := merged.mergeFrom(biomes[i]); err != nil {
+			return err
+		}
+	}
+
+	// The biome's own envs come first, then the inherited envs in linearization order
+	var order []string
+	for _, biome := range biomes {
+		order = append(order, biome.EnvKeys()...)
+	}
+
+	bc.Environment = make(map[string]interface{}, len(merged.Environment))
+	bc.EnvSources = make(map[string]EnvSource, len(merged.Environment))
+	bc.EnvOrder = nil
+
+	for _, env := range order {
+		if val, exists := merged.Environment[env]; exists {
+			bc.AddEnvFrom(env, val, merged.EnvSources[env])
+		}
+	}
+
+	// Params are ordered the same way as the envs
+	var paramOrder []string
+	for _, biome := range biomes {
+		paramOrder = append(paramOrder, biome.ParamKeys()...)
+	}
+
+	bc.Params = make(map[string]ParamConfig, len(merged.Params))
+	bc.ParamOrder = nil
+
+	for _, name := range paramOrder {
+		if _, exists := bc.Params[name]; !exists {
+			bc.Params[name] = merged.Params[name]
+			bc.ParamOrder = append(bc.ParamOrder, name)
+		}
+	}
+
+	bc.AwsProfile = merged.AwsProfile
+	bc.ProfileFile = merged.ProfileFile
+	bc.EnvFiles = merged.EnvFiles
+	bc.Sources = merged.Sources
+	bc.Isolate = merged.Isolate
+	bc.Unset = merged.Unset
+	bc.AllowEnv = merged.AllowEnv
+	bc.Commands = merged.Commands
+	bc.Parents = lineage[1:]
+
+	return nil
+}
+
+// Lineage will return the names of the biomes this biome inherits from, in the order they take precedence
+func (bc *BiomeConfig) Lineage(genepool map[string]*BiomeConfig) ([]string, error) {
+	lineage, err := linearize(bc.Name, bc, genepool, nil, make(map[string][]string))
+	if err != nil {
+		return nil, err
+	}
+
+	return lineage[1:], nil
+}
+
+// mergeFrom will merge the biome on top of the configuration merged so far
+func (merged *BiomeConfig) mergeFrom(biome *BiomeConfig) error {
+	// AWS Profile (the nearest biome that sets one wins)
+	if biome.AwsProfile != "" {
+		merged.AwsProfile = biome.AwsProfile
+		merged.ProfileFile = biome.ProfileFile
+	}
+
+	// Env files (the nearest biome's files come last so they take precedence)
+	for _, file := range biome.EnvFiles {
+		if !containsEnvFile(merged.EnvFiles, file) {
+			merged.EnvFiles = append(merged.EnvFiles, file)
+		}
+	}
+
+	// Host environment (the nearest biome that sets isolate wins, removed and allowed variables add up)
+	if biome.Isolate != nil {
+		merged.Isolate = biome.Isolate
+	}
+
+	merged.Unset = appendUnique(merged.Unset, biome.Unset...)
+	merged.AllowEnv = appendUnique(merged.AllowEnv, biome.AllowEnv...)
+
+	// Params (the nearest biome that declares a param wins)
+	for name, param := range biome.Params {
+		merged.Params[name] = param
+	}
+
+	// Commands
+	switch biome.Merge.Commands {
+	case "", MERGE_PREPEND:
+		merged.Commands = append(merged.Commands, biome.Commands...)
+	case MERGE_APPEND:
+		merged.Commands = append(append([]string{}, biome.Commands...), merged.Commands...)
+	case MERGE_REPLACE:
+		merged.Commands = append([]string{}, biome.Commands...)
+	default:
+		return fmt.Errorf("biome '%s' has an unknown commands merge strategy '%s', expected one of %s, %s or %s",
+			biome.Name, biome.Merge.Commands, MERGE_PREPEND, MERGE_APPEND, MERGE_REPLACE)
+	}
+
+	// Envs
+	switch biome.Merge.Environment {
+	case "", MERGE_MERGE:
+	case MERGE_REPLACE:
+		merged.Environment = make(map[string]interface{})
+		merged.EnvSources = make(map[string]EnvSource)
+		merged.Sources = nil
+	default:
+		return fmt.Errorf("biome '%s' has an unknown environment merge strategy '%s', expected one of %s or %s",
+			biome.Name, biome.Merge.Environment, MERGE_MERGE, MERGE_REPLACE)
+	}
+
+	// Sources (the nearest biome's sources come last so they take precedence)
+	merged.Sources = append(merged.Sources, biome.Sources...)
+
+	for _, env := range biome.Merge.RemoveEnv {
+		delete(merged.Environment, env)
+		delete(merged.EnvSources, env)
+	}
+
+	// Envs that prepend or append build on the inherited value instead of replacing it
+	for env, val := range biome.Environment {
+		inherited, exists := merged.Environment[env]
+		merged.Environment[env] = layerEnv(val, inherited, merged.EnvSources[env], exists)
+		merged.EnvSources[env] = biome.EnvSource(env)
+	}
+
+	return nil
+}
+
+// appendUnique will append the values that aren't already in the list
+func appendUnique(list StringList, vals ...string) StringList {
+	for _, val := range vals {
+		if !Contains(list, val) {
+			list = append(list, val)
+		}
+	}
+
+	return list
+}
+
+func containsEnvFile(files EnvFileList, file EnvFile) bool {
+	for _, f := range files {
+		if f == file {
+			return true
+		}
+	}
+
+	return false
+}
+
+// linearize will compute the C3 linearization of the biome, starting with the biome itself
+func linearize(name string, biome *BiomeConfig, genepool map[string]*BiomeConfig, path []string, memo map[string][]string) ([]string, error) {
+	if lineage, exists := memo[name]; exists {
+		return lineage, nil
+	}
+
+	// Circular inheritance check
+	for i, p := range path {
+		if p == name {
+			cycle := append(append([]string{}, path[i:]...), name)
+			return nil, fmt.Errorf("circular biome inheritance found: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	path = append(append([]string{}, path...), name)
+
+	seqs := make([][]string, 0, len(biome.Inheritance)+1)
+	seen := make(map[string]bool, len(biome.Inheritance))
+
+	for _, parent := range biome.Inheritance {
+		if seen[parent] {
+			return nil, fmt.Errorf("biome '%s' inherits from '%s' more than once", name, parent)
+		}
+
+		seen[parent] = true
+
+		// Does it exist?
+		if _, exists := genepool[parent]; !exists {
+			return nil, fmt.Errorf("can not find inherited biome %s", parent)
+		}
+
+		lineage, err := linearize(parent, genepool[parent], genepool, path, memo)
+		if err != nil {
+			return nil, err
+		}
+
+		seqs = append(seqs, lineage)
+	}
+
+	seqs = append(seqs, append([]string{}, biome.Inheritance...))
+
+	merged, err := c3Merge(seqs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine the inheritance order of biome '%s': %v", name, err)
+	}
+
+	lineage := append([]string{name}, merged...)
+	memo[name] = lineage
+
+	return lineage, nil
+}
+
+// c3Merge will merge the parent linearizations, always taking the first head that is not in the tail of another list
+func c3Merge(seqs [][]string) ([]string, error) {
+	var result []string
+
+	for {
+		remaining := seqs[:0]
+		for _, seq := range seqs {
+			if len(seq) > 0 {
+				remaining = append(remaining, seq)
+			}
+		}
+
+		seqs = remaining
+		if len(seqs) == 0 {
+			return result, nil
+		}
+
+		next := ""
+		for _, seq := range seqs {
+			if !inTail(seq[0], seqs) {
+				next = seq[0]
+				break
+			}
+		}
+
+		if next == "" {
+			heads := make([]string, 0, len(seqs))
+			for _, seq := range seqs {
+				heads = append(heads, fmt.Sprintf("'%s'", seq[0]))
+			}
+
+			return nil, fmt.Errorf("the inherited biomes %s are required in conflicting orders", strings.Join(heads, ", "))
+		}
+
+		result = append(result, next)
+
+		for i, seq := range seqs {
+			if seq[0] == next {
+				seqs[i] = seq[1:]
+			}
+		}
+	}
+}
+
+// inTail will report whether the name shows up after the head of any of the lists
+func inTail(name string, seqs [][]string) bool {
+	for _, seq := range seqs {
+		for _, n := range seq[1:] {
+			if n == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,385 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInherit(t *testing.T) {
+	// Helper for building a genepool out of a list of biomes
+	getGenepool := func(biomes ...*BiomeConfig) map[string]*BiomeConfig {
+		genepool := make(map[string]*BiomeConfig, len(biomes))
+		for _, b := range biomes {
+			genepool[b.Name] = b
+		}
+
+		return genepool
+	}
+
+	t.Run("should order inherited envs after the child envs, nearest parent first", func(t *testing.T) {
+		// Assemble
+		grandparent := &BiomeConfig{
+			Name:        "grandparent",
+			Environment: map[string]interface{}{"G2": "g", "G1": "g", "SHARED": "grandparent"},
+			EnvOrder:    []string{"G2", "G1", "SHARED"},
+		}
+		parent := &BiomeConfig{
+			Name:        "parent",
+			Inheritance: StringList{"grandparent"},
+			Environment: map[string]interface{}{"P1": "p", "SHARED": "parent"},
+			EnvOrder:    []string{"P1", "SHARED"},
+		}
+		child := &BiomeConfig{
+			Name:        "child",
+			Inheritance: StringList{"parent"},
+			Environment: map[string]interface{}{"C2": "c", "C1": "c"},
+			EnvOrder:    []string{"C2", "C1"},
+		}
+		genepool := map[string]*BiomeConfig{
+			"grandparent": grandparent,
+			"parent":      parent,
+			"child":       child,
+		}
+
+		// Act
+		err := child.Inherit(genepool)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"C2", "C1", "P1", "SHARED", "G2", "G1"}, child.EnvKeys())
+		assert.Equal(t, "parent", child.Environment["SHARED"])
+	})
+
+	t.Run("should inherit params with the nearest declaration winning", func(t *testing.T) {
+		// Assemble
+		parentRegion, childRegion := "us-east-1", "us-west-2"
+		parent := &BiomeConfig{
+			Name: "parent",
+			Params: map[string]ParamConfig{
+				"region":  {Default: &parentRegion},
+				"service": {},
+			},
+			ParamOrder: []string{"region", "service"},
+		}
+		child := &BiomeConfig{
+			Name:        "child",
+			Inheritance: StringList{"parent"},
+			Params:      map[string]ParamConfig{"region": {Default: &childRegion}},
+			ParamOrder:  []string{"region"},
+		}
+
+		// Act
+		err := child.Inherit(getGenepool(parent, child))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"region", "service"}, child.ParamKeys())
+		assert.Equal(t, childRegion, *child.Params["region"].Default)
+	})
+
+	t.Run("should add up the host environment settings", func(t *testing.T) {
+		// Assemble
+		isolate := true
+		parent := &BiomeConfig{Name: "parent", Isolate: &isolate, Unset: StringList{"AWS_*"}, AllowEnv: StringList{"SSH_*"}}
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"parent"}, Unset: StringList{"KUBECONFIG", "AWS_*"}}
+
+		// Act
+		err := child.Inherit(getGenepool(parent, child))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.True(t, child.IsIsolated())
+		assert.Equal(t, StringList{"AWS_*", "KUBECONFIG"}, child.Unset)
+		assert.Equal(t, StringList{"SSH_*"}, child.AllowEnv)
+	})
+
+	t.Run("should let a child opt out of its parent's isolation", func(t *testing.T) {
+		// Assemble
+		isolate, optOut := true, false
+		grandparent := &BiomeConfig{Name: "grandparent", Isolate: &isolate}
+		parent := &BiomeConfig{Name: "parent", Inheritance: StringList{"grandparent"}}
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"parent"}, Isolate: &optOut}
+
+		inheritedParent := parent.Copy()
+
+		// Act
+		parentErr := inheritedParent.Inherit(getGenepool(grandparent, parent))
+		childErr := child.Inherit(getGenepool(grandparent, parent, child))
+
+		// Assert
+		assert.Nil(t, parentErr)
+		assert.True(t, inheritedParent.IsIsolated())
+		assert.Nil(t, childErr)
+		assert.False(t, child.IsIsolated())
+	})
+
+	t.Run("should layer envs that prepend or append on the inherited env", func(t *testing.T) {
+		// Assemble
+		parentPath := map[string]interface{}{"value": "./bin", "mode": "prepend"}
+		childPath := map[string]interface{}{"value": "./tools", "mode": "prepend"}
+		parent := &BiomeConfig{
+			Name:        "parent",
+			Environment: map[string]interface{}{"PATH": parentPath, "LOG_LEVEL": "info"},
+		}
+		child := &BiomeConfig{
+			Name:        "child",
+			Inheritance: StringList{"parent"},
+			Environment: map[string]interface{}{"PATH": childPath, "LOG_LEVEL": map[string]interface{}{"value": "debug", "mode": "default"}},
+		}
+
+		// Act
+		err := child.Inherit(getGenepool(parent, child))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, LayeredEnv{Config: childPath, Base: parentPath}, child.Environment["PATH"])
+		assert.Equal(t, map[string]interface{}{"value": "debug", "mode": "default"}, child.Environment["LOG_LEVEL"])
+	})
+
+	t.Run("should keep the directory of the inherited env when the biomes are in different files", func(t *testing.T) {
+		// Assemble
+		parentPath := map[string]interface{}{"from_file": "certs/ca.crt", "mode": "append"}
+		childPath := map[string]interface{}{"from_file": "certs/ca.crt", "mode": "append"}
+		parent := &BiomeConfig{Name: "parent", SourceFile: "/etc/biome.d/shared.yaml"}
+		parent.AddEnv("CA_BUNDLE", parentPath)
+		child := &BiomeConfig{Name: "child", SourceFile: "/src/app/.biome.yaml", Inheritance: StringList{"parent"}}
+		child.AddEnv("CA_BUNDLE", childPath)
+
+		// Act
+		err := child.Inherit(getGenepool(parent, child))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, LayeredEnv{Config: childPath, Base: parentPath, BaseDir: "/etc/biome.d"}, child.Environment["CA_BUNDLE"])
+		assert.Equal(t, "/src/app/.biome.yaml", child.EnvSource("CA_BUNDLE").File)
+	})
+
+	t.Run("should linearize multiple inheritance with C3", func(t *testing.T) {
+		// Assemble
+		base := &BiomeConfig{Name: "base", Commands: []string{"base"}}
+		aws := &BiomeConfig{Name: "aws", Inheritance: StringList{"base"}, Commands: []string{"aws"}}
+		k8s := &BiomeConfig{Name: "k8s", Inheritance: StringList{"base"}, Commands: []string{"k8s"}}
+		flags := &BiomeConfig{Name: "flags", Commands: []string{"flags"}}
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"aws", "k8s", "flags"}, Commands: []string{"child"}}
+		genepool := getGenepool(base, aws, k8s, flags, child)
+
+		// Act
+		lineage, err := linearize(child.Name, child, genepool, nil, make(map[string][]string))
+		inheritErr := child.Inherit(genepool)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Nil(t, inheritErr)
+		assert.Equal(t, []string{"child", "aws", "k8s", "base", "flags"}, lineage)
+		assert.Equal(t, []string{"flags", "base", "k8s", "aws", "child"}, child.Commands)
+	})
+
+	t.Run("should give the earlier parent precedence", func(t *testing.T) {
+		// Assemble
+		first := &BiomeConfig{Name: "first", AwsProfile: "first", Environment: map[string]interface{}{"SHARED": "first"}}
+		second := &BiomeConfig{Name: "second", AwsProfile: "second", EnvFiles: EnvFileList{{Path: "second.env"}}, Environment: map[string]interface{}{"SHARED": "second"}}
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"first", "second"}, Environment: map[string]interface{}{}}
+
+		// Act
+		err := child.Inherit(getGenepool(first, second, child))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "first", child.AwsProfile)
+		assert.Equal(t, EnvFileList{{Path: "second.env"}}, child.EnvFiles)
+		assert.Equal(t, "first", child.Environment["SHARED"])
+	})
+
+	t.Run("should load the inherited env files before the biome's own files", func(t *testing.T) {
+		// Assemble
+		base := &BiomeConfig{Name: "base", EnvFiles: EnvFileList{{Path: "base.env"}, {Path: "shared.env"}}}
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"base"}, EnvFiles: EnvFileList{{Path: "shared.env"}, {Path: ".env.local", Optional: true}}}
+
+		// Act
+		err := child.Inherit(getGenepool(base, child))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, EnvFileList{{Path: "base.env"}, {Path: "shared.env"}, {Path: ".env.local", Optional: true}}, child.EnvFiles)
+	})
+
+	t.Run("should load the inherited sources before the biome's own sources", func(t *testing.T) {
+		// Assemble
+		base := &BiomeConfig{Name: "base", Sources: []SourceConfig{{SecretArn: "shared"}}}
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"base"}, Sources: []SourceConfig{{SsmPath: "/app/child"}}}
+		replaced := &BiomeConfig{Name: "replaced", Inheritance: StringList{"base"}, Merge: MergeConfig{Environment: MERGE_REPLACE}}
+
+		// Act
+		childErr := child.Inherit(getGenepool(base, child))
+		replacedErr := replaced.Inherit(getGenepool(base, replaced))
+
+		// Assert
+		assert.Nil(t, childErr)
+		assert.Nil(t, replacedErr)
+		assert.Equal(t, []SourceConfig{{SecretArn: "shared"}, {SsmPath: "/app/child"}}, child.Sources)
+		assert.Empty(t, replaced.Sources)
+	})
+
+	t.Run("should apply the commands merge strategy", func(t *testing.T) {
+		for strategy, expected := range map[string][]string{
+			MERGE_PREPEND: {"parent", "child"},
+			MERGE_APPEND:  {"child", "parent"},
+			MERGE_REPLACE: {"child"},
+		} {
+			// Assemble
+			parent := &BiomeConfig{Name: "parent", Commands: []string{"parent"}}
+			child := &BiomeConfig{
+				Name:        "child",
+				Inheritance: StringList{"parent"},
+				Commands:    []string{"child"},
+				Merge:       MergeConfig{Commands: strategy},
+			}
+
+			// Act
+			err := child.Inherit(getGenepool(parent, child))
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, expected, child.Commands, strategy)
+			assert.Equal(t, []string{"parent"}, parent.Commands)
+		}
+	})
+
+	t.Run("should remove inherited envs", func(t *testing.T) {
+		// Assemble
+		parent := &BiomeConfig{Name: "parent", Environment: map[string]interface{}{"KEEP": "k", "DROP": "d"}}
+		child := &BiomeConfig{
+			Name:        "child",
+			Inheritance: StringList{"parent"},
+			Merge:       MergeConfig{RemoveEnv: []string{"DROP"}},
+		}
+
+		// Act
+		err := child.Inherit(getGenepool(parent, child))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"KEEP": "k"}, child.Environment)
+	})
+
+	t.Run("should replace the inherited envs", func(t *testing.T) {
+		// Assemble
+		parent := &BiomeConfig{Name: "parent", Environment: map[string]interface{}{"PARENT": "p"}}
+		child := &BiomeConfig{
+			Name:        "child",
+			Inheritance: StringList{"parent"},
+			Environment: map[string]interface{}{"CHILD": "c"},
+			Merge:       MergeConfig{Environment: MERGE_REPLACE},
+		}
+
+		// Act
+		err := child.Inherit(getGenepool(parent, child))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"CHILD": "c"}, child.Environment)
+	})
+
+	t.Run("should report an unknown merge strategy", func(t *testing.T) {
+		// Assemble
+		parent := &BiomeConfig{Name: "parent"}
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"parent"}, Merge: MergeConfig{Commands: "shuffle"}}
+
+		// Act
+		err := child.Inherit(getGenepool(parent, child))
+
+		// Assert
+		assert.ErrorContains(t, err, "shuffle")
+	})
+
+	t.Run("should report a missing biome", func(t *testing.T) {
+		// Assemble
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"ghost"}}
+
+		// Act
+		err := child.Inherit(getGenepool(child))
+
+		// Assert
+		assert.ErrorContains(t, err, "ghost")
+	})
+
+	t.Run("should report circular inheritance with the biomes involved", func(t *testing.T) {
+		// Assemble
+		a := &BiomeConfig{Name: "a", Inheritance: StringList{"b"}}
+		b := &BiomeConfig{Name: "b", Inheritance: StringList{"c"}}
+		c := &BiomeConfig{Name: "c", Inheritance: StringList{"a"}}
+
+		// Act
+		err := a.Inherit(getGenepool(a, b, c))
+
+		// Assert
+		assert.ErrorContains(t, err, "a -> b -> c -> a")
+	})
+
+	t.Run("should report conflicting inheritance orders", func(t *testing.T) {
+		// Assemble
+		x := &BiomeConfig{Name: "x"}
+		y := &BiomeConfig{Name: "y"}
+		xy := &BiomeConfig{Name: "xy", Inheritance: StringList{"x", "y"}}
+		yx := &BiomeConfig{Name: "yx", Inheritance: StringList{"y", "x"}}
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"xy", "yx"}}
+
+		// Act
+		err := child.Inherit(getGenepool(x, y, xy, yx, child))
+
+		// Assert
+		assert.ErrorContains(t, err, "conflicting orders")
+		assert.ErrorContains(t, err, "'x'")
+		assert.ErrorContains(t, err, "'y'")
+	})
+
+	t.Run("should report a parent listed more than once", func(t *testing.T) {
+		// Assemble
+		parent := &BiomeConfig{Name: "parent"}
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"parent", "parent"}}
+
+		// Act
+		err := child.Inherit(getGenepool(parent, child))
+
+		// Assert
+		assert.ErrorContains(t, err, "more than once")
+	})
+}
+
+func TestLineage(t *testing.T) {
+	getGenepool := func(biomes ...*BiomeConfig) map[string]*BiomeConfig {
+		genepool := make(map[string]*BiomeConfig, len(biomes))
+		for _, b := range biomes {
+			genepool[b.Name] = b
+		}
+
+		return genepool
+	}
+
+	t.Run("should list the parents in the order they take precedence", func(t *testing.T) {
+		// Assemble
+		base := &BiomeConfig{Name: "base"}
+		aws := &BiomeConfig{Name: "aws", Inheritance: StringList{"base"}}
+		k8s := &BiomeConfig{Name: "k8s", Inheritance: StringList{"base"}}
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"aws", "k8s"}}
+
+		// Act
+		lineage, err := child.Lineage(getGenepool(base, aws, k8s, child))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"aws", "k8s", "base"}, lineage)
+	})
+
+	t.Run("should report a missing parent", func(t *testing.T) {
+		// Assemble
+		child := &BiomeConfig{Name: "child", Inheritance: StringList{"ghost"}}
+
+		// Act
+		_, err := child.Lineage(getGenepool(child))
+
+		// Assert
+		assert.ErrorContains(t, err, "ghost")
+	})
+}
@@ -0,0 +1,124 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const PARAM_ENV_PREFIX = "BIOME_PARAM_"
+
+// ParamConfig describes a value that is supplied when the biome is activated
+type ParamConfig struct {
+	Description string   `yaml:"description" desc:"What the param is used for, shown when prompting for it"`
+	Default     *string  `yaml:"default" desc:"The value used when the param isn't supplied, params without a default are required"`
+	Allowed     []string `yaml:"allowed" desc:"The only values the param can be set to"`
+}
+
+// Required will determine if the param has to be supplied
+func (p ParamConfig) Required() bool {
+	return p.Default == nil
+}
+
+// ParamPrompt is used to ask for the value of a required param that wasn't supplied
+type ParamPrompt func(name string, param ParamConfig) (string, error)
+
+// ParamKeys will return the param names in their declared order, followed by any
+// params without a declared order in alphabetical order
+func (bc *BiomeConfig) ParamKeys() []string {
+	keys := make([]string, 0, len(bc.Params))
+	seen := make(map[string]bool, len(bc.Params))
+
+	for _, key := range bc.ParamOrder {
+		if _, exists := bc.Params[key]; exists && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	var unordered []string
+	for key := range bc.Params {
+		if !seen[key] {
+			unordered = append(unordered, key)
+		}
+	}
+
+	sort.Strings(unordered)
+
+	return append(keys, unordered...)
+}
+
+// ResolveParams will work out the value of every param the biome declares
+//
+// Supplied values are used first, then the param's default. Required params that weren't
+// supplied are asked for with the prompt, or reported as missing if there is no prompt
+func (bc *BiomeConfig) ResolveParams(values map[string]string, prompt ParamPrompt) (map[string]string, error) {
+	return bc.resolveParams(values, prompt, false)
+}
+
+// KnownParams will work out the value of the params that were supplied or have a default without
+// prompting, required params that weren't supplied are left out
+func (bc *BiomeConfig) KnownParams(values map[string]string) (map[string]string, error) {
+	return bc.resolveParams(values, nil, true)
+}
+
+func (bc *BiomeConfig) resolveParams(values map[string]string, prompt ParamPrompt, skipMissing bool) (map[string]string, error) {
+	var unknown []string
+	for name := range values {
+		if _, exists := bc.Params[name]; !exists {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("biome '%s' has no param '%s'", bc.Name, strings.Join(unknown, "', '"))
+	}
+
+	params := make(map[string]string, len(bc.Params))
+	for _, name := range bc.ParamKeys() {
+		param := bc.Params[name]
+		val, exists := values[name]
+
+		if !exists && !param.Required() {
+			val, exists = *param.Default, true
+		}
+
+		if !exists && skipMissing {
+			continue
+		}
+
+		if !exists {
+			if prompt == nil {
+				return nil, fmt.Errorf("biome '%s' requires the param '%s', set it with -p %s=<value>", bc.Name, name, name)
+			}
+
+			var err error
+			if val, err = prompt(name, param); err != nil {
+				return nil, fmt.Errorf("unable to read the param '%s': %v", name, err)
+			}
+		}
+
+		if len(param.Allowed) > 0 && !Contains(param.Allowed, val) {
+			return nil, fmt.Errorf("invalid value '%s' for the param '%s', expected one of: %s", val, name, strings.Join(param.Allowed, ", "))
+		}
+
+		params[name] = val
+	}
+
+	return params, nil
+}
+
+// ParamEnvName will build the name of the environment variable a param is exported to
+func ParamEnvName(name string) string {
+	return PARAM_ENV_PREFIX + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,126 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveParams(t *testing.T) {
+	defaultRegion := "us-east-1"
+
+	getTestBiome := func() *BiomeConfig {
+		return &BiomeConfig{
+			Name: "svc-deploy",
+			Params: map[string]ParamConfig{
+				"service": {Allowed: []string{"billing", "search"}},
+				"region":  {Default: &defaultRegion, Allowed: []string{"us-east-1", "us-west-2"}},
+			},
+			ParamOrder: []string{"service", "region"},
+		}
+	}
+
+	t.Run("should use the supplied values and fall back to the defaults", func(t *testing.T) {
+		// Assemble
+		biome := getTestBiome()
+
+		// Act
+		params, err := biome.ResolveParams(map[string]string{"service": "billing"}, nil)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"service": "billing", "region": "us-east-1"}, params)
+	})
+
+	t.Run("should prompt for missing required params in declared order", func(t *testing.T) {
+		// Assemble
+		biome := getTestBiome()
+		biome.Params["zone"] = ParamConfig{}
+		biome.ParamOrder = append(biome.ParamOrder, "zone")
+
+		var prompted []string
+		prompt := func(name string, param ParamConfig) (string, error) {
+			prompted = append(prompted, name)
+			if name == "service" {
+				return "search", nil
+			}
+
+			return "a", nil
+		}
+
+		// Act
+		params, err := biome.ResolveParams(map[string]string{}, prompt)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"service", "zone"}, prompted)
+		assert.Equal(t, "search", params["service"])
+	})
+
+	t.Run("should leave out the missing required params without prompting", func(t *testing.T) {
+		// Assemble
+		biome := getTestBiome()
+
+		// Act
+		params, err := biome.KnownParams(map[string]string{"region": "us-west-2"})
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"region": "us-west-2"}, params)
+	})
+
+	t.Run("should report missing required params when there is no prompt", func(t *testing.T) {
+		// Assemble
+		biome := getTestBiome()
+
+		// Act
+		_, err := biome.ResolveParams(nil, nil)
+
+		// Assert
+		assert.NotNil(t, err)
+		assert.ErrorContains(t, err, "biome 'svc-deploy' requires the param 'service', set it with -p service=<value>")
+	})
+
+	t.Run("should report prompt errors", func(t *testing.T) {
+		// Assemble
+		biome := getTestBiome()
+		testErr := fmt.Errorf("EOF")
+
+		// Act
+		_, err := biome.ResolveParams(nil, func(string, ParamConfig) (string, error) { return "", testErr })
+
+		// Assert
+		assert.NotNil(t, err)
+		assert.ErrorContains(t, err, "unable to read the param 'service': EOF")
+	})
+
+	t.Run("should report values that are not allowed", func(t *testing.T) {
+		// Assemble
+		biome := getTestBiome()
+
+		// Act
+		_, err := biome.ResolveParams(map[string]string{"service": "billing", "region": "eu-west-1"}, nil)
+
+		// Assert
+		assert.NotNil(t, err)
+		assert.ErrorContains(t, err, "invalid value 'eu-west-1' for the param 'region', expected one of: us-east-1, us-west-2")
+	})
+
+	t.Run("should report params the biome doesn't declare", func(t *testing.T) {
+		// Assemble
+		biome := getTestBiome()
+
+		// Act
+		_, err := biome.ResolveParams(map[string]string{"service": "billing", "stage": "prod"}, nil)
+
+		// Assert
+		assert.NotNil(t, err)
+		assert.ErrorContains(t, err, "biome 'svc-deploy' has no param 'stage'")
+	})
+
+	t.Run("should build the environment variable names of params", func(t *testing.T) {
+		assert.Equal(t, "BIOME_PARAM_SERVICE", ParamEnvName("service"))
+		assert.Equal(t, "BIOME_PARAM_AWS_REGION_2", ParamEnvName("aws-region.2"))
+	})
+}
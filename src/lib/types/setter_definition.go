@@ -0,0 +1,27 @@
+package types
+
+const (
+	SETTER_VALUE_STRING = "string"
+	SETTER_VALUE_BOOL   = "bool"
+	SETTER_VALUE_SCALAR = "scalar" // A string, number or boolean
+	SETTER_VALUE_ANY    = "any"    // Any value apart from null
+)
+
+// SetterKey describes a key that can be used in a complex environment config
+type SetterKey struct {
+	Name        string
+	Type        string // The type of value the key takes
+	Required    bool
+	Enum        []string    // The allowed values, if limited
+	Const       interface{} // The only value allowed, if any (ex: from_cli must be true)
+	Description string
+}
+
+// SetterDefinition describes a complex environment config and the keys it accepts
+type SetterDefinition struct {
+	Selector    SetterKey   // The key that selects the setter
+	Keys        []SetterKey // The other keys the setter accepts
+	ExactlyOne  []string    // Exactly one of these keys must be set, a boolean key set to false isn't set
+	Description string
+	Secret      bool // The values are secrets, biome show redacts them
+}
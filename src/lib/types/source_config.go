@@ -0,0 +1,156 @@
+package types
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The kinds of sources, named after the key that selects them
+const (
+	SOURCE_SECRET_ARN = "secret_arn" // Every key of a JSON secret in Secrets Manager
+	SOURCE_SSM_PATH   = "ssm_path"   // Every parameter under a Parameter Store path
+	SOURCE_FILE       = "file"       // Every variable in a dotenv, JSON or YAML file
+)
+
+// The case transforms a source can apply to its keys
+const (
+	SOURCE_CASE_UPPER = "upper"
+	SOURCE_CASE_LOWER = "lower"
+)
+
+// SourceConfig is an entry of sources, which expands a secret, a Parameter Store path or a file into many variables
+type SourceConfig struct {
+	SecretArn  string            `yaml:"secret_arn" desc:"A Secrets Manager secret holding a JSON object, each key is a variable"`
+	SsmPath    string            `yaml:"ssm_path" desc:"A Parameter Store path, each parameter under it is a variable named by its path below it with / replaced by _"`
+	SsmDecrypt bool              `yaml:"ssm_decrypt" desc:"Decrypt the SecureString parameters under ssm_path"`
+	File       string            `yaml:"file" desc:"A dotenv, JSON or YAML file, relative to the config file"`
+	Format     string            `yaml:"format" enum:"dotenv,json,yaml" desc:"The format of the file, detected from its extension by default"`
+	Optional   bool              `yaml:"optional" desc:"Skip the file when it doesn't exist"`
+	Prefix     string            `yaml:"prefix" desc:"Added to the start of each variable name (ex: DB_)"`
+	Case       string            `yaml:"case" enum:"upper,lower" desc:"Change the case of each key before the prefix is added"`
+	Include    StringList        `yaml:"include" desc:"Only load the keys matching these glob patterns"`
+	Exclude    StringList        `yaml:"exclude" desc:"Skip the keys matching these glob patterns"`
+	Rename     map[string]string `yaml:"rename" desc:"The variable name to use for a key, used as is without the prefix or case"`
+	Biome      string            `yaml:"-"` // The biome that declared the source
+	ConfigFile string            `yaml:"-"` // The config file the source was declared in, the file is relative to it
+	Line       int               `yaml:"-"` // The line in the config file the source starts on
+}
+
+func (s *SourceConfig) UnmarshalYAML(node *yaml.Node) error {
+	// Decode into a type without this method to use the default decoding
+	type sourceConfig SourceConfig
+
+	var source sourceConfig
+	if err := node.Decode(&source); err != nil {
+		return err
+	}
+
+	*s = SourceConfig(source)
+	s.Line = node.Line
+
+	if s.kindCount() != 1 {
+		return fmt.Errorf("line %d: a source needs exactly one of '%s', '%s' or '%s'", node.Line, SOURCE_SECRET_ARN, SOURCE_SSM_PATH, SOURCE_FILE)
+	}
+
+	switch s.Case {
+	case "", SOURCE_CASE_UPPER, SOURCE_CASE_LOWER:
+	default:
+		return fmt.Errorf("line %d: unknown case '%s', expected one of %s or %s", node.Line, s.Case, SOURCE_CASE_UPPER, SOURCE_CASE_LOWER)
+	}
+
+	for _, pattern := range append(append([]string{}, s.Include...), s.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("line %d: invalid pattern '%s': %v", node.Line, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// kindCount will count the keys that select the kind of source which are set, a valid source has one
+func (s SourceConfig) kindCount() int {
+	var count int
+	for _, val := range []string{s.SecretArn, s.SsmPath, s.File} {
+		if val != "" {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Kind will return the key that selects the kind of source (secret_arn, ssm_path or file)
+func (s SourceConfig) Kind() string {
+	switch {
+	case s.SecretArn != "":
+		return SOURCE_SECRET_ARN
+	case s.SsmPath != "":
+		return SOURCE_SSM_PATH
+	}
+
+	return SOURCE_FILE
+}
+
+// EnvFile will return the file of a file source so it is found and read the same as a load_env file
+func (s SourceConfig) EnvFile() EnvFile {
+	file := EnvFile{Path: s.File, Format: s.Format, Optional: s.Optional}
+	if s.ConfigFile != "" {
+		file.Dir = filepath.Dir(s.ConfigFile)
+	}
+
+	return file
+}
+
+// String will describe the source by its kind and what it loads (ex: ssm_path /app/prod/)
+func (s SourceConfig) String() string {
+	switch s.Kind() {
+	case SOURCE_SECRET_ARN:
+		return SOURCE_SECRET_ARN + " " + s.SecretArn
+	case SOURCE_SSM_PATH:
+		return SOURCE_SSM_PATH + " " + s.SsmPath
+	}
+
+	return SOURCE_FILE + " " + s.EnvFile().ResolvedPath()
+}
+
+// VariableName will return the name of the variable a key of the source sets, or false if the key is filtered out
+//
+// Keys are matched against include and exclude as they are in the source. A renamed key uses its new
+// name as is, any other key has its case changed and then the prefix added
+func (s SourceConfig) VariableName(key string) (string, bool) {
+	if len(s.Include) > 0 && !matchAny(s.Include, key) {
+		return "", false
+	}
+
+	if matchAny(s.Exclude, key) {
+		return "", false
+	}
+
+	if name, exists := s.Rename[key]; exists {
+		return name, true
+	}
+
+	switch s.Case {
+	case SOURCE_CASE_UPPER:
+		key = strings.ToUpper(key)
+	case SOURCE_CASE_LOWER:
+		key = strings.ToLower(key)
+	}
+
+	return s.Prefix + key, true
+}
+
+// matchAny will determine if the key matches any of the glob patterns
+func matchAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+
+	return false
+}
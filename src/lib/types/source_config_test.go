@@ -0,0 +1,103 @@
+package types
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSourceConfig(t *testing.T) {
+	t.Run("VariableName", func(t *testing.T) {
+		t.Run("should add the prefix after changing the case", func(t *testing.T) {
+			// Assemble
+			source := SourceConfig{SsmPath: "/app/prod", Prefix: "APP_", Case: SOURCE_CASE_UPPER}
+
+			// Act
+			name, ok := source.VariableName("db_host")
+
+			// Assert
+			assert.True(t, ok)
+			assert.Equal(t, "APP_DB_HOST", name)
+		})
+
+		t.Run("should filter the keys with include and exclude", func(t *testing.T) {
+			// Assemble
+			source := SourceConfig{SecretArn: "prod/app", Include: StringList{"db_*", "api_key"}, Exclude: StringList{"db_admin_*"}}
+
+			// Act
+			_, hostOk := source.VariableName("db_host")
+			_, adminOk := source.VariableName("db_admin_password")
+			_, otherOk := source.VariableName("smtp_host")
+
+			// Assert
+			assert.True(t, hostOk)
+			assert.False(t, adminOk)
+			assert.False(t, otherOk)
+		})
+
+		t.Run("should use a renamed key as is", func(t *testing.T) {
+			// Assemble
+			source := SourceConfig{SecretArn: "prod/app", Prefix: "APP_", Case: SOURCE_CASE_UPPER, Rename: map[string]string{"pw": "DATABASE_PASSWORD"}}
+
+			// Act
+			name, ok := source.VariableName("pw")
+
+			// Assert
+			assert.True(t, ok)
+			assert.Equal(t, "DATABASE_PASSWORD", name)
+		})
+	})
+
+	t.Run("UnmarshalYAML", func(t *testing.T) {
+		t.Run("should decode the source with its line", func(t *testing.T) {
+			// Assemble
+			doc := "name: app\nsources:\n  - file: secrets.env\n    prefix: APP_\n    include: DB_*\n"
+
+			// Act
+			var bc BiomeConfig
+			err := yaml.Unmarshal([]byte(doc), &bc)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, []SourceConfig{{File: "secrets.env", Prefix: "APP_", Include: StringList{"DB_*"}, Line: 3}}, bc.Sources)
+			assert.Equal(t, SOURCE_FILE, bc.Sources[0].Kind())
+		})
+
+		t.Run("should report a source without exactly one kind", func(t *testing.T) {
+			// Act
+			var none, both SourceConfig
+			noneErr := yaml.Unmarshal([]byte("prefix: APP_"), &none)
+			bothErr := yaml.Unmarshal([]byte("secret_arn: prod/app\nfile: app.env"), &both)
+
+			// Assert
+			assert.EqualError(t, noneErr, "line 1: a source needs exactly one of 'secret_arn', 'ssm_path' or 'file'")
+			assert.EqualError(t, bothErr, "line 1: a source needs exactly one of 'secret_arn', 'ssm_path' or 'file'")
+		})
+
+		t.Run("should report an unknown case and invalid patterns", func(t *testing.T) {
+			// Act
+			var caseSource, patternSource SourceConfig
+			caseErr := yaml.Unmarshal([]byte("file: app.env\ncase: title"), &caseSource)
+			patternErr := yaml.Unmarshal([]byte("file: app.env\nexclude: '[a'"), &patternSource)
+
+			// Assert
+			assert.EqualError(t, caseErr, "line 1: unknown case 'title', expected one of upper or lower")
+			assert.ErrorContains(t, patternErr, "line 1: invalid pattern '[a'")
+		})
+	})
+
+	t.Run("should describe the source", func(t *testing.T) {
+		// Assemble
+		dir := t.TempDir()
+
+		// Act
+		secret := SourceConfig{SecretArn: "prod/app"}.String()
+		file := SourceConfig{File: "app.env", ConfigFile: filepath.Join(dir, ".biome.yaml")}.String()
+
+		// Assert
+		assert.Equal(t, "secret_arn prod/app", secret)
+		assert.Equal(t, "file "+filepath.Join(dir, "app.env"), file)
+	})
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StringList is a list of strings that can be written in YAML as either a single string or a sequence
+type StringList []string
+
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			*l = nil
+			return nil
+		}
+
+		*l = StringList{node.Value}
+	case yaml.SequenceNode:
+		var vals []string
+		if err := node.Decode(&vals); err != nil {
+			return err
+		}
+
+		*l = vals
+	default:
+		return fmt.Errorf("line %d: expected a string or a list of strings", node.Line)
+	}
+
+	return nil
+}
+
+// Contains will determine if the list has the value
+func Contains(list []string, val string) bool {
+	for _, item := range list {
+		if item == val {
+			return true
+		}
+	}
+
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 )
@@ -17,6 +18,19 @@ type awsSecretsManagerServiceIfc interface {
 // The interface for the Secrets Manager Repository
 type SecretsManagerIfc interface {
 	GetSecretString(string) (string, error)
+	GetSecret(arn string, version SecretVersion) (*SecretValue, error)
+}
+
+// SecretVersion selects the version of a secret, the current version (AWSCURRENT) is used when both are empty
+type SecretVersion struct {
+	Id    string
+	Stage string
+}
+
+// SecretValue is the value of a secret, only one of String or Binary is set
+type SecretValue struct {
+	String string
+	Binary []byte
 }
 
 // The Secrets Manager Repository for proxying requests to secrets manager
@@ -41,18 +55,37 @@ func NewSecretsManagerRepo() (*SecretsManager, error) {
 	}, nil
 }
 
-// GetSecretString will pull the secret from Secrets Manager and return the string value
+// GetSecretString will pull the current version of the secret from Secrets Manager and return the string value
 func (smrepo SecretsManager) GetSecretString(arn string) (string, error) {
-	response, err := smrepo.client.GetSecretValue(
-		context.TODO(),
-		&secretsmanager.GetSecretValueInput{
-			SecretId: &arn,
-		},
-	)
+	secret, err := smrepo.GetSecret(arn, SecretVersion{})
+	if err != nil {
+		return "", err
+	}
 
+	return secret.String, nil
+}
+
+// GetSecret will pull a version of the secret from Secrets Manager, whether it is a string or binary
+func (smrepo SecretsManager) GetSecret(arn string, version SecretVersion) (*SecretValue, error) {
+	input := &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(arn),
+	}
+
+	if version.Id != "" {
+		input.VersionId = aws.String(version.Id)
+	}
+
+	if version.Stage != "" {
+		input.VersionStage = aws.String(version.Stage)
+	}
+
+	response, err := smrepo.client.GetSecretValue(context.TODO(), input)
 	if err != nil {
-		return "", fmt.Errorf("unable to retreive '%s' from secrets manager: %v", arn, err)
+		return nil, fmt.Errorf("unable to retrieve '%s' from secrets manager: %v", arn, err)
 	}
 
-	return *response.SecretString, nil
+	return &SecretValue{
+		String: aws.ToString(response.SecretString),
+		Binary: response.SecretBinary,
+	}, nil
 }
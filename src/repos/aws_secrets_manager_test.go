@@ -0,0 +1,68 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSecretsManagerClient records the last request and returns the output it was given
+type fakeSecretsManagerClient struct {
+	input  *secretsmanager.GetSecretValueInput
+	output *secretsmanager.GetSecretValueOutput
+	err    error
+}
+
+func (c *fakeSecretsManagerClient) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	c.input = params
+	return c.output, c.err
+}
+
+func TestSecretsManager(t *testing.T) {
+	t.Run("should request the version of the secret", func(t *testing.T) {
+		// Assemble
+		client := &fakeSecretsManagerClient{output: &secretsmanager.GetSecretValueOutput{SecretString: aws.String("hunter2")}}
+		repo := SecretsManager{client: client}
+
+		// Act
+		secret, err := repo.GetSecret("prod/app", SecretVersion{Stage: "AWSPREVIOUS"})
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, &SecretValue{String: "hunter2"}, secret)
+		assert.Equal(t, "prod/app", aws.ToString(client.input.SecretId))
+		assert.Equal(t, "AWSPREVIOUS", aws.ToString(client.input.VersionStage))
+		assert.Nil(t, client.input.VersionId)
+	})
+
+	t.Run("should return binary secrets", func(t *testing.T) {
+		// Assemble
+		client := &fakeSecretsManagerClient{output: &secretsmanager.GetSecretValueOutput{SecretBinary: []byte{0xde, 0xad}}}
+		repo := SecretsManager{client: client}
+
+		// Act
+		secret, secretErr := repo.GetSecret("prod/cert", SecretVersion{Id: "v1"})
+		str, strErr := repo.GetSecretString("prod/cert")
+
+		// Assert
+		assert.Nil(t, secretErr)
+		assert.Nil(t, strErr)
+		assert.Equal(t, []byte{0xde, 0xad}, secret.Binary)
+		assert.Empty(t, str)
+	})
+
+	t.Run("should report an error getting the secret", func(t *testing.T) {
+		// Assemble
+		repo := SecretsManager{client: &fakeSecretsManagerClient{err: fmt.Errorf("access denied")}}
+
+		// Act
+		_, err := repo.GetSecret("prod/app", SecretVersion{})
+
+		// Assert
+		assert.EqualError(t, err, "unable to retrieve 'prod/app' from secrets manager: access denied")
+	})
+}
@@ -0,0 +1,125 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// SSM_MAX_PARAMETERS_PER_REQUEST is the most names GetParameters accepts in one call
+const SSM_MAX_PARAMETERS_PER_REQUEST = 10
+
+// The interface for the AWS SDK Systems Manager Service
+type awsSsmServiceIfc interface {
+	GetParameters(ctx context.Context, params *ssm.GetParametersInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersOutput, error)
+	GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
+}
+
+// The interface for the Parameter Store Repository
+type ParameterStoreIfc interface {
+	GetParameters(names []string, decrypt bool) (map[string]SsmParameter, error)
+	GetParametersByPath(path string, decrypt bool) ([]SsmParameter, error)
+}
+
+// SsmParameter is a parameter loaded from Parameter Store
+type SsmParameter struct {
+	Name    string
+	Value   string
+	Type    string // String, StringList or SecureString
+	Version int64
+}
+
+// The Parameter Store Repository for proxying requests to AWS Systems Manager
+type ParameterStore struct {
+	client awsSsmServiceIfc
+}
+
+// NewParameterStoreRepo builds the ParameterStore repository and its dependencies
+func NewParameterStoreRepo() (*ParameterStore, error) {
+	// Setup the systems manager client
+	cfg, err := config.LoadDefaultConfig(
+		context.TODO(),
+		config.WithDefaultRegion(os.Getenv("AWS_DEFAULT_REGION")),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS configuration: %v", err)
+	}
+
+	return &ParameterStore{
+		client: ssm.NewFromConfig(cfg),
+	}, nil
+}
+
+// GetParameters will pull the parameters from Parameter Store, SSM_MAX_PARAMETERS_PER_REQUEST at a time
+//
+// Names can have a version (/app/db_host:3) or label (/app/db_host:prod) selector and the
+// parameters are returned by the name they were requested with. Parameters that don't exist are left out
+func (repo ParameterStore) GetParameters(names []string, decrypt bool) (map[string]SsmParameter, error) {
+	params := make(map[string]SsmParameter)
+
+	for start := 0; start < len(names); start += SSM_MAX_PARAMETERS_PER_REQUEST {
+		end := start + SSM_MAX_PARAMETERS_PER_REQUEST
+		if end > len(names) {
+			end = len(names)
+		}
+
+		response, err := repo.client.GetParameters(
+			context.TODO(),
+			&ssm.GetParametersInput{
+				Names:          names[start:end],
+				WithDecryption: decrypt,
+			},
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve parameters from parameter store: %v", err)
+		}
+
+		for _, param := range response.Parameters {
+			name := aws.ToString(param.Name)
+
+			params[name+aws.ToString(param.Selector)] = SsmParameter{
+				Name:    name,
+				Value:   aws.ToString(param.Value),
+				Type:    string(param.Type),
+				Version: param.Version,
+			}
+		}
+	}
+
+	return params, nil
+}
+
+// GetParametersByPath will pull every parameter under the path from Parameter Store, including nested paths
+func (repo ParameterStore) GetParametersByPath(path string, decrypt bool) ([]SsmParameter, error) {
+	var params []SsmParameter
+
+	paginator := ssm.NewGetParametersByPathPaginator(repo.client, &ssm.GetParametersByPathInput{
+		Path:           &path,
+		Recursive:      true,
+		WithDecryption: decrypt,
+	})
+
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve the parameters under '%s' from parameter store: %v", path, err)
+		}
+
+		for _, param := range response.Parameters {
+			params = append(params, SsmParameter{
+				Name:    aws.ToString(param.Name),
+				Value:   aws.ToString(param.Value),
+				Type:    string(param.Type),
+				Version: param.Version,
+			})
+		}
+	}
+
+	return params, nil
+}
@@ -0,0 +1,127 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSsmClient returns a parameter for every name it is asked for, apart from the ones named missing,
+// and the pages of parameters under a path
+type fakeSsmClient struct {
+	requests [][]string
+	pages    [][]ssmtypes.Parameter
+}
+
+func (c *fakeSsmClient) GetParameters(ctx context.Context, params *ssm.GetParametersInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersOutput, error) {
+	c.requests = append(c.requests, params.Names)
+
+	output := &ssm.GetParametersOutput{}
+	for _, name := range params.Names {
+		if name == "missing" {
+			output.InvalidParameters = append(output.InvalidParameters, name)
+			continue
+		}
+
+		output.Parameters = append(output.Parameters, ssmtypes.Parameter{
+			Name:  aws.String(name),
+			Value: aws.String("value of " + name),
+			Type:  ssmtypes.ParameterTypeString,
+		})
+	}
+
+	return output, nil
+}
+
+func (c *fakeSsmClient) GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	page := 0
+	if params.NextToken != nil {
+		page, _ = strconv.Atoi(*params.NextToken)
+	}
+
+	output := &ssm.GetParametersByPathOutput{Parameters: c.pages[page]}
+	if page+1 < len(c.pages) {
+		output.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+
+	return output, nil
+}
+
+func TestParameterStore(t *testing.T) {
+	t.Run("should request the parameters in batches", func(t *testing.T) {
+		// Assemble
+		client := &fakeSsmClient{}
+		repo := ParameterStore{client: client}
+
+		var names []string
+		for i := 0; i < 12; i++ {
+			names = append(names, fmt.Sprintf("/app/param%d", i))
+		}
+
+		// Act
+		params, err := repo.GetParameters(append(names, "missing"), false)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Len(t, client.requests, 2)
+		assert.Len(t, client.requests[0], SSM_MAX_PARAMETERS_PER_REQUEST)
+		assert.Len(t, params, 12)
+		assert.Equal(t, "value of /app/param11", params["/app/param11"].Value)
+		assert.NotContains(t, params, "missing")
+	})
+
+	t.Run("should return parameters by the name they were requested with", func(t *testing.T) {
+		// Assemble
+		client := &fakeSsmClient{}
+		repo := ParameterStore{client: selectorSsmClient{client}}
+
+		// Act
+		params, err := repo.GetParameters([]string{"/app/db_host:3"}, true)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, SsmParameter{Name: "/app/db_host", Value: "db.internal", Type: "String", Version: 3}, params["/app/db_host:3"])
+	})
+
+	t.Run("should load every page of parameters under a path", func(t *testing.T) {
+		// Assemble
+		client := &fakeSsmClient{pages: [][]ssmtypes.Parameter{
+			{{Name: aws.String("/app/prod/db/host"), Value: aws.String("db.internal"), Type: ssmtypes.ParameterTypeString}},
+			{{Name: aws.String("/app/prod/api_key"), Value: aws.String("abc"), Type: ssmtypes.ParameterTypeSecureString}},
+		}}
+		repo := ParameterStore{client: client}
+
+		// Act
+		params, err := repo.GetParametersByPath("/app/prod", true)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, []SsmParameter{
+			{Name: "/app/prod/db/host", Value: "db.internal", Type: "String"},
+			{Name: "/app/prod/api_key", Value: "abc", Type: "SecureString"},
+		}, params)
+	})
+}
+
+// selectorSsmClient returns a parameter requested with a selector the way AWS does, with the selector split out
+type selectorSsmClient struct {
+	*fakeSsmClient
+}
+
+func (c selectorSsmClient) GetParameters(ctx context.Context, params *ssm.GetParametersInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersOutput, error) {
+	return &ssm.GetParametersOutput{
+		Parameters: []ssmtypes.Parameter{{
+			Name:     aws.String("/app/db_host"),
+			Selector: aws.String(":3"),
+			Value:    aws.String("db.internal"),
+			Type:     ssmtypes.ParameterTypeString,
+			Version:  3,
+		}},
+	}, nil
+}
@@ -19,6 +19,12 @@ type AwsStsRepositoryIfc interface {
 	SetAwsEnvs(*types.AwsEnvConfig)
 }
 
+// The interface for looking up the account and region of the current AWS session
+type AwsIdentityIfc interface {
+	GetAccountID() (string, error)
+	GetRegion() (string, error)
+}
+
 // AwsStsRepository handles setting up an AWS Session
 type AwsStsRepository struct{}
 
@@ -60,6 +66,46 @@ func (repo AwsStsRepository) ConfigureSession(profile string) (*types.AwsEnvConf
 	}, nil
 }
 
+// GetAccountID will look up the account ID of the credentials in the current environment
+func (repo AwsStsRepository) GetAccountID() (string, error) {
+	cfg, err := repo.loadEnvConfig()
+	if err != nil {
+		return "", err
+	}
+
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("unable to get the AWS caller identity: %v", err)
+	}
+
+	return *identity.Account, nil
+}
+
+// GetRegion will look up the region the SDK resolves from the current environment (AWS_REGION,
+// the profile's region or AWS_DEFAULT_REGION)
+func (repo AwsStsRepository) GetRegion() (string, error) {
+	cfg, err := repo.loadEnvConfig()
+	if err != nil {
+		return "", err
+	}
+
+	return cfg.Region, nil
+}
+
+// loadEnvConfig will load the AWS config of the current environment
+func (repo AwsStsRepository) loadEnvConfig() (aws.Config, error) {
+	cfg, err := config.LoadDefaultConfig(
+		context.TODO(),
+		config.WithDefaultRegion(os.Getenv("AWS_DEFAULT_REGION")),
+	)
+
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("unable to load AWS configuration: %v", err)
+	}
+
+	return cfg, nil
+}
+
 func (repo AwsStsRepository) loadProfile(profile string) (config.SharedConfig, error) {
 	profileCfg, err := config.LoadSharedConfigProfile(context.TODO(), profile)
 	if err != nil {
@@ -0,0 +1,44 @@
+package repos
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAwsStsRepository(t *testing.T) {
+	t.Run("should resolve the region the same as the SDK", func(t *testing.T) {
+		// Assemble
+		noConfig := filepath.Join(t.TempDir(), "missing")
+		envs := map[string]string{
+			"AWS_REGION":                  "eu-west-1",
+			"AWS_DEFAULT_REGION":          "us-east-1",
+			"AWS_PROFILE":                 "",
+			"AWS_CONFIG_FILE":             noConfig,
+			"AWS_SHARED_CREDENTIALS_FILE": noConfig,
+		}
+
+		for name, val := range envs {
+			hostVal, exists := os.LookupEnv(name)
+			os.Setenv(name, val)
+
+			name := name
+			t.Cleanup(func() {
+				if exists {
+					os.Setenv(name, hostVal)
+				} else {
+					os.Unsetenv(name)
+				}
+			})
+		}
+
+		// Act
+		region, err := NewAwsStsRepository().GetRegion()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "eu-west-1", region)
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/jeff-roche/biome/src/lib/fileio"
 	"github.com/jeff-roche/biome/src/lib/types"
@@ -13,44 +15,311 @@ import (
 
 type BiomeFileParserIfc interface {
 	FindBiome(biomeName string, searchFiles []string) (*types.BiomeConfig, error)
+	LoadBiomes(searchFiles []string) ([]*types.BiomeConfig, error)
 }
 
-type BiomeFileParser struct{}
+type BiomeFileParser struct {
+	strict    bool           // Report every problem instead of skipping invalid documents
+	local     bool           // Parsing a local override file, which can only set some keys
+	validator biomeValidator // Used to validate documents in strict mode
+}
+
+// LOCAL_CONFIG_SUFFIX marks a local override file (.biome.local.yaml) that is merged on top of the config file next to it
+const LOCAL_CONFIG_SUFFIX = ".local"
+
+// localKeys are the keys a local override file can set
+var localKeys = []string{"version", "name", "aws_profile", "environment"}
 
 func NewBiomeFileParser() *BiomeFileParser {
 	return &BiomeFileParser{}
 }
 
+// NewStrictBiomeFileParser will build a parser that validates every document against the config
+// types and the setter definitions, failing with the file, line and column of every problem found
+func NewStrictBiomeFileParser(setterDefinitions []types.SetterDefinition) *BiomeFileParser {
+	return &BiomeFileParser{
+		strict: true,
+		validator: biomeValidator{
+			setterDefinitions: setterDefinitions,
+		},
+	}
+}
+
+// FindBiome will load every biome in the search files (and the files they include) into a single
+// genepool and return the requested biome with its inheritance applied
+//
+// When a biome name is defined more than once, the first definition found wins. Files are searched
+// in the order given and a file's own biomes come before the biomes of the files it includes
 func (parser BiomeFileParser) FindBiome(biomeName string, searchFiles []string) (*types.BiomeConfig, error) {
-	// If we have any errors with the file, continue on to the next one
+	pool, err := parser.loadGenepool(searchFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pool.errors) > 0 {
+		return nil, pool.errors
+	}
+
+	biome, exists := pool.biomes[biomeName]
+	if !exists {
+		return nil, fmt.Errorf("unable to locate the '%s' biome, searched: %s", biomeName, strings.Join(pool.searched, ", "))
+	}
+
+	if err := biome.Inherit(pool.biomes); err != nil {
+		return nil, err
+	}
+
+	return biome, nil
+}
+
+// LoadBiomes will load every biome in the search files (and the files they include) in the order they were found
+//
+// Inheritance is not applied so the biomes can be inspected as they were written.
+// Biomes that are overridden by an earlier definition with the same name are left out
+func (parser BiomeFileParser) LoadBiomes(searchFiles []string) ([]*types.BiomeConfig, error) {
+	pool, err := parser.loadGenepool(searchFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pool.errors) > 0 {
+		return nil, pool.errors
+	}
+
+	return pool.ordered, nil
+}
+
+// genepool holds every biome that was loaded from the config files
+type genepool struct {
+	biomes    map[string]*types.BiomeConfig
+	ordered   []*types.BiomeConfig // The biomes in the order they were found
+	searched  []string             // Every file that was searched, in order
+	loaded    map[string]bool      // The files that have already been loaded
+	overrides []*types.BiomeConfig // Biomes from local override files, in the order they were found
+	errors    ConfigErrors         // Problems found in strict mode
+}
+
+// loadGenepool will load the biomes from all of the search files that exist
+func (parser BiomeFileParser) loadGenepool(searchFiles []string) (*genepool, error) {
+	pool := &genepool{
+		biomes: make(map[string]*types.BiomeConfig),
+		loaded: make(map[string]bool),
+	}
+
+	// If we have any errors with a search file, continue on to the next one
 	for _, fPath := range searchFiles {
 		if !fileio.FileExists(fPath) {
+			pool.searched = append(pool.searched, fPath)
 			continue
 		}
 
-		// Open the file and try to load the biome from it
-		freader, err := os.Open(fPath)
-		if err != nil {
-			continue
+		if err := parser.loadFile(fPath, pool, nil, false); err != nil {
+			return nil, err
 		}
+	}
 
-		biomes := parser.loadBiomes(freader)
-		if _, exists := biomes[biomeName]; exists {
-			biome := biomes[biomeName]
+	// Overrides are applied from the lowest precedence file up so the override next to the first file wins
+	for i := len(pool.overrides) - 1; i >= 0; i-- {
+		local := pool.overrides[i]
 
-			if err := biome.Inherit(biomes); err != nil {
-				return nil, err
+		biome, exists := pool.biomes[local.Name]
+		if !exists {
+			if parser.strict {
+				pool.errors = append(pool.errors, ConfigError{
+					File:    local.SourceFile,
+					Line:    local.SourceLine,
+					Message: fmt.Sprintf("local override for unknown biome '%s'", local.Name),
+				})
 			}
 
-			return biome, nil
+			continue
+		}
+
+		biome.ApplyLocal(local)
+	}
+
+	return pool, nil
+}
+
+// loadFile will load the biomes from the file into the genepool, followed by the biomes of any files it includes
+func (parser BiomeFileParser) loadFile(fPath string, pool *genepool, includeStack []string, required bool) error {
+	if abs, err := filepath.Abs(fPath); err == nil {
+		fPath = abs
+	}
+
+	// Circular include check
+	for i, f := range includeStack {
+		if f == fPath {
+			cycle := append(append([]string{}, includeStack[i:]...), fPath)
+			return fmt.Errorf("circular include found: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	if pool.loaded[fPath] {
+		return nil
+	}
+
+	pool.loaded[fPath] = true
+	pool.searched = append(pool.searched, fPath)
+
+	// Open the file and load the biomes from it
+	freader, err := os.Open(fPath)
+	if err != nil {
+		if required {
+			return fmt.Errorf("unable to open included file: %v", err)
 		}
+
+		return nil
+	}
+	defer freader.Close()
+
+	docs := parser.parseDocuments(freader)
+
+	for _, err := range docs.errors {
+		err.File = fPath
+		pool.errors = append(pool.errors, err)
+	}
+
+	for _, biome := range docs.biomes {
+		if _, exists := pool.biomes[biome.Name]; exists {
+			continue
+		}
+
+		biome.SourceFile = fPath
+		for i := range biome.EnvFiles {
+			biome.EnvFiles[i].Dir = filepath.Dir(fPath)
+		}
+
+		for i := range biome.Sources {
+			biome.Sources[i].ConfigFile = fPath
+			biome.Sources[i].Biome = biome.Name
+		}
+
+		for env, source := range biome.EnvSources {
+			source.File = fPath
+			biome.EnvSources[env] = source
+		}
+
+		pool.biomes[biome.Name] = biome
+		pool.ordered = append(pool.ordered, biome)
 	}
 
-	return nil, fmt.Errorf("unable to locate the '%s' biome", biomeName)
+	parser.loadLocalFile(fPath, pool)
+
+	// Includes are relative to the including file
+	includeStack = append(append([]string{}, includeStack...), fPath)
+	for _, include := range docs.includes {
+		if err := parser.loadFile(resolveIncludePath(fPath, include), pool, includeStack, true); err != nil {
+			return fmt.Errorf("%s: %v", fPath, err)
+		}
+	}
+
+	return nil
+}
+
+// loadLocalFile will load the overrides from the local override file next to the config file, if there is one
+func (parser BiomeFileParser) loadLocalFile(fPath string, pool *genepool) {
+	var localPath string
+	for _, candidate := range LocalConfigPaths(fPath) {
+		if fileio.FileExists(candidate) {
+			localPath = candidate
+			break
+		}
+	}
+
+	if localPath == "" || pool.loaded[localPath] {
+		return
+	}
+
+	pool.loaded[localPath] = true
+
+	freader, err := os.Open(localPath)
+	if err != nil {
+		return
+	}
+	defer freader.Close()
+
+	localParser := parser
+	localParser.local = true
+	docs := localParser.parseDocuments(freader)
+
+	for _, err := range docs.errors {
+		err.File = localPath
+		pool.errors = append(pool.errors, err)
+	}
+
+	for _, local := range docs.biomes {
+		local.SourceFile = localPath
+		for env, source := range local.EnvSources {
+			source.File = localPath
+			local.EnvSources[env] = source
+		}
+
+		pool.overrides = append(pool.overrides, local)
+	}
+}
+
+// LocalConfigPaths will return the local override files that can sit next to a config file, in the
+// order they are looked for (.biome.yaml -> .biome.local.yaml, .biome.local.yml)
+//
+// A local override file doesn't have a local override file of its own
+func LocalConfigPaths(fPath string) []string {
+	ext := filepath.Ext(fPath)
+	base := strings.TrimSuffix(fPath, ext)
+
+	if strings.HasSuffix(base, LOCAL_CONFIG_SUFFIX) {
+		return nil
+	}
+
+	paths := []string{base + LOCAL_CONFIG_SUFFIX + ext}
+	for _, other := range []string{".yaml", ".yml"} {
+		if other != ext {
+			paths = append(paths, base+LOCAL_CONFIG_SUFFIX+other)
+		}
+	}
+
+	return paths
+}
+
+// IsLocalConfig will determine if the file is a local override file
+func IsLocalConfig(fPath string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(fPath, filepath.Ext(fPath)), LOCAL_CONFIG_SUFFIX)
+}
+
+// resolveIncludePath will resolve the include relative to the directory of the including file
+func resolveIncludePath(from string, include string) string {
+	include = fileio.ExpandHome(include)
+
+	if filepath.IsAbs(include) {
+		return include
+	}
+
+	return filepath.Join(filepath.Dir(from), include)
+}
+
+// biomeDocuments holds everything that was parsed from the documents of a single config file
+type biomeDocuments struct {
+	biomes   []*types.BiomeConfig // Biomes in the order they were declared
+	includes []string             // Included files in the order they were declared
+	errors   []ConfigError        // Problems found in strict mode
 }
 
 // Load biomes will load in all biomes from the given io.Reader
 func (parser BiomeFileParser) loadBiomes(fcontents io.Reader) map[string]*types.BiomeConfig {
+	biomes := make(map[string]*types.BiomeConfig)
+
+	for _, biome := range parser.parseDocuments(fcontents).biomes {
+		biomes[biome.Name] = biome
+	}
+
+	return biomes
+}
+
+// parseDocuments will parse the biomes and includes out of every document in the given io.Reader
+//
+// Documents that can't be decoded are skipped unless the parser is strict, in which case every
+// problem is reported. A YAML syntax error stops the parsing of the remaining documents
+func (parser BiomeFileParser) parseDocuments(fcontents io.Reader) biomeDocuments {
 	buff := new(bytes.Buffer)
 	buff.ReadFrom(fcontents)
 
@@ -58,27 +327,177 @@ func (parser BiomeFileParser) loadBiomes(fcontents io.Reader) map[string]*types.
 	reader := bytes.NewReader(buff.Bytes())
 	decoder := yaml.NewDecoder(reader)
 
-	biomes := make(map[string]*types.BiomeConfig)
+	var docs biomeDocuments
+	names := make(map[string]*yaml.Node)
 
 	for {
+		var doc yaml.Node
 		var biomeCfg types.BiomeConfig
 
-		if err := decoder.Decode(&biomeCfg); err != nil {
-			if err != io.EOF {
-				continue
+		if err := decoder.Decode(&doc); err != nil {
+			if err != io.EOF && parser.strict {
+				docs.errors = append(docs.errors, newYamlConfigError(nil, err)...)
 			}
 
 			break
 		}
 
+		// Documents written for a newer format would be misread, so they are always reported
+		if err := checkVersion(&doc); err != nil {
+			docs.errors = append(docs.errors, *err)
+			continue
+		}
+
+		// Local override files can only set some keys, anything else would be silently ignored
+		if parser.local {
+			if errs := checkLocalDocument(&doc); len(errs) > 0 {
+				docs.errors = append(docs.errors, errs...)
+				continue
+			}
+		}
+
+		if parser.strict {
+			errs := parser.validator.validateDocument(&doc)
+
+			// Duplicate biome names in the same file
+			if nameNode := getMappingValue(&doc, "name"); nameNode != nil && nameNode.Kind == yaml.ScalarNode {
+				if first, exists := names[nameNode.Value]; exists {
+					errs = append(errs, newConfigError(nameNode, "duplicate biome name '%s', first defined at line %d", nameNode.Value, first.Line))
+				} else {
+					names[nameNode.Value] = nameNode
+				}
+			}
+
+			if len(errs) > 0 {
+				docs.errors = append(docs.errors, errs...)
+				continue
+			}
+		}
+
+		if err := doc.Decode(&biomeCfg); err != nil {
+			if parser.strict {
+				docs.errors = append(docs.errors, newYamlConfigError(nil, err)...)
+			}
+
+			continue
+		}
+
+		docs.includes = append(docs.includes, biomeCfg.Include...)
+
 		if biomeCfg.Name == "" {
 			continue
 		}
 
-		biomes[biomeCfg.Name] = &biomeCfg
+		biomeCfg.EnvOrder = getMappingKeys(&doc, "environment")
+		biomeCfg.ParamOrder = getMappingKeys(&doc, "params")
+		biomeCfg.SourceLine = getMappingValue(&doc, "name").Line
+		biomeCfg.EnvSources = getEnvSources(&doc, biomeCfg.Name)
+		docs.biomes = append(docs.biomes, &biomeCfg)
 	}
 
-	return biomes
+	return docs
+}
+
+// checkVersion will report a document that is written in a version of the config format this binary can't read
+func checkVersion(doc *yaml.Node) *ConfigError {
+	node := getMappingValue(doc, "version")
+	if node == nil {
+		return nil
+	}
+
+	// Versions that aren't numbers are reported by the strict validation
+	var version int
+	if err := node.Decode(&version); err != nil {
+		return nil
+	}
+
+	var err ConfigError
+	switch {
+	case version < 1:
+		err = newConfigError(node, "unknown config version %d, the first version is 1", version)
+	case version > types.CONFIG_VERSION:
+		err = newConfigError(node, "config version %d is newer than this version of biome supports (up to version %d), upgrade biome to use this file", version, types.CONFIG_VERSION)
+	default:
+		return nil
+	}
+
+	return &err
+}
+
+// checkLocalDocument will report the keys of a local override document that can't be overridden
+func checkLocalDocument(doc *yaml.Node) []ConfigError {
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var errs []ConfigError
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if !types.Contains(localKeys, key.Value) {
+			errs = append(errs, newConfigError(key, "'%s' can't be set in a local override file, only aws_profile and environment can be overridden", key.Value))
+		}
+	}
+
+	if getMappingValue(root, "name") == nil {
+		errs = append(errs, newConfigError(root, "a local override needs the name of the biome it overrides"))
+	}
+
+	return errs
+}
+
+// getMappingKeys will return the keys of a mapping block (environment, params) in the order they were declared
+func getMappingKeys(doc *yaml.Node, key string) []string {
+	block := getMappingValue(doc, key)
+	if block == nil || block.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	keys := make([]string, 0, len(block.Content)/2)
+	for i := 0; i+1 < len(block.Content); i += 2 {
+		keys = append(keys, block.Content[i].Value)
+	}
+
+	return keys
+}
+
+// getEnvSources will record the line each environment variable of the biome is declared on
+func getEnvSources(doc *yaml.Node, biomeName string) map[string]types.EnvSource {
+	sources := make(map[string]types.EnvSource)
+
+	block := getMappingValue(doc, "environment")
+	if block == nil || block.Kind != yaml.MappingNode {
+		return sources
+	}
+
+	for i := 0; i+1 < len(block.Content); i += 2 {
+		sources[block.Content[i].Value] = types.EnvSource{Biome: biomeName, Line: block.Content[i].Line}
+	}
+
+	return sources
+}
+
+// getMappingValue will return the value node for the key in a mapping (or document) node
+func getMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
 }
 
 // loadBiomeFromFile will search for the biome in the file and if it finds it will parse and return it
@@ -0,0 +1,437 @@
+package repos
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBiomeFileParser(t *testing.T) {
+	t.Run("loadBiomes", func(t *testing.T) {
+		t.Run("should keep the declared order of the environment", func(t *testing.T) {
+			// Assemble
+			parser := NewBiomeFileParser()
+			contents := `
+name: ordered
+environment:
+  ZULU: z
+  ALPHA: a
+  MIKE:
+    from_dragoman: "[ENC,...]"
+---
+name: other
+environment:
+  B: b
+  A: a
+`
+
+			// Act
+			biomes := parser.loadBiomes(strings.NewReader(contents))
+
+			// Assert
+			assert.Len(t, biomes, 2)
+			assert.Equal(t, []string{"ZULU", "ALPHA", "MIKE"}, biomes["ordered"].EnvKeys())
+			assert.Equal(t, []string{"B", "A"}, biomes["other"].EnvKeys())
+		})
+
+		t.Run("should keep the declared order of the params", func(t *testing.T) {
+			// Assemble
+			parser := NewBiomeFileParser()
+			contents := `
+name: svc-deploy
+params:
+  service:
+    allowed: [billing, search]
+  region:
+    default: us-east-1
+`
+
+			// Act
+			biomes := parser.loadBiomes(strings.NewReader(contents))
+
+			// Assert
+			assert.Equal(t, []string{"service", "region"}, biomes["svc-deploy"].ParamKeys())
+			assert.True(t, biomes["svc-deploy"].Params["service"].Required())
+			assert.Equal(t, "us-east-1", *biomes["svc-deploy"].Params["region"].Default)
+		})
+
+		t.Run("should accept a single parent or a list of parents", func(t *testing.T) {
+			// Assemble
+			parser := NewBiomeFileParser()
+			contents := `
+name: single
+inherit_from: base
+---
+name: multiple
+inherit_from: [aws, k8s]
+merge:
+  commands: replace
+  remove_env: [DEBUG]
+`
+
+			// Act
+			biomes := parser.loadBiomes(strings.NewReader(contents))
+
+			// Assert
+			assert.Equal(t, types.StringList{"base"}, biomes["single"].Inheritance)
+			assert.Equal(t, types.StringList{"aws", "k8s"}, biomes["multiple"].Inheritance)
+			assert.Equal(t, types.MERGE_REPLACE, biomes["multiple"].Merge.Commands)
+			assert.Equal(t, []string{"DEBUG"}, biomes["multiple"].Merge.RemoveEnv)
+		})
+
+		t.Run("should report documents written for a newer config version", func(t *testing.T) {
+			// Assemble
+			parser := NewBiomeFileParser()
+			contents := `
+version: 2
+name: current
+---
+version: 99
+name: future
+`
+
+			// Act
+			docs := parser.parseDocuments(strings.NewReader(contents))
+
+			// Assert
+			assert.Len(t, docs.biomes, 1)
+			assert.Equal(t, "current", docs.biomes[0].Name)
+			assert.Len(t, docs.errors, 1)
+			assert.Equal(t, 5, docs.errors[0].Line)
+			assert.Contains(t, docs.errors[0].Message, "config version 99 is newer than this version of biome supports (up to version 2)")
+		})
+
+		t.Run("should skip documents without a name", func(t *testing.T) {
+			// Assemble
+			parser := NewBiomeFileParser()
+			contents := `
+environment:
+  A: a
+---
+name: named
+`
+
+			// Act
+			biomes := parser.loadBiomes(strings.NewReader(contents))
+
+			// Assert
+			assert.Len(t, biomes, 1)
+			assert.Contains(t, biomes, "named")
+		})
+	})
+
+	t.Run("FindBiome", func(t *testing.T) {
+		// Helper for writing a config file into the test directory
+		writeFile := func(t *testing.T, fpath string, contents string) string {
+			if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(fpath, []byte(contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			return fpath
+		}
+
+		t.Run("should inherit from a biome in another search file", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			project := writeFile(t, filepath.Join(dir, "project", ".biome.yaml"), `
+name: project
+inherit_from: shared
+environment:
+  PROJECT: p
+`)
+			home := writeFile(t, filepath.Join(dir, "home", ".biome.yaml"), `
+name: shared
+aws_profile: shared-profile
+environment:
+  SHARED: s
+`)
+			parser := NewBiomeFileParser()
+
+			// Act
+			biome, err := parser.FindBiome("project", []string{project, home})
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, "shared-profile", biome.AwsProfile)
+			assert.Equal(t, []string{"PROJECT", "SHARED"}, biome.EnvKeys())
+			assert.Equal(t, project, biome.SourceFile)
+		})
+
+		t.Run("should record where each inherited variable was declared", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			project := writeFile(t, filepath.Join(dir, "project", ".biome.yaml"), `
+name: project
+inherit_from: shared
+environment:
+  PROJECT: p
+  SHARED: overridden
+`)
+			home := writeFile(t, filepath.Join(dir, "home", ".biome.yaml"), `
+name: shared
+environment:
+  OTHER: o
+  SHARED: s
+`)
+			parser := NewBiomeFileParser()
+
+			// Act
+			biome, err := parser.FindBiome("project", []string{project, home})
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"shared"}, biome.Parents)
+			assert.Equal(t, types.EnvSource{Biome: "project", File: project, Line: 5}, biome.EnvSource("PROJECT"))
+			assert.Equal(t, types.EnvSource{Biome: "project", File: project, Line: 6}, biome.EnvSource("SHARED"))
+			assert.Equal(t, types.EnvSource{Biome: "shared", File: home, Line: 4}, biome.EnvSource("OTHER"))
+		})
+
+		t.Run("should resolve env files relative to the config file", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			project := writeFile(t, filepath.Join(dir, "project", ".biome.yaml"), `
+name: project
+load_env:
+  - .env
+  - path: /abs/.env
+    optional: true
+`)
+			parser := NewBiomeFileParser()
+
+			// Act
+			biome, err := parser.FindBiome("project", []string{project})
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, filepath.Join(dir, "project", ".env"), biome.EnvFiles[0].ResolvedPath())
+			assert.Equal(t, "/abs/.env", biome.EnvFiles[1].ResolvedPath())
+			assert.True(t, biome.EnvFiles[1].Optional)
+		})
+
+		t.Run("should load biomes from included files relative to the including file", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			main := writeFile(t, filepath.Join(dir, ".biome.yaml"), `
+include: mixins/aws.yaml
+---
+name: main
+inherit_from: aws-staging
+`)
+			mixin := writeFile(t, filepath.Join(dir, "mixins", "aws.yaml"), `
+include: [k8s.yaml]
+---
+name: aws-staging
+inherit_from: k8s-staging
+aws_profile: staging
+`)
+			writeFile(t, filepath.Join(dir, "mixins", "k8s.yaml"), `
+name: k8s-staging
+commands:
+  - kubectx staging
+`)
+			parser := NewBiomeFileParser()
+
+			// Act
+			biome, err := parser.FindBiome("main", []string{main})
+			included, includedErr := parser.FindBiome("aws-staging", []string{main})
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Nil(t, includedErr)
+			assert.Equal(t, "staging", biome.AwsProfile)
+			assert.Equal(t, []string{"kubectx staging"}, biome.Commands)
+			assert.Equal(t, mixin, included.SourceFile)
+		})
+
+		t.Run("should prefer the first definition of a biome", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			first := writeFile(t, filepath.Join(dir, "first.yaml"), "name: dup\naws_profile: first\n")
+			second := writeFile(t, filepath.Join(dir, "second.yaml"), "name: dup\naws_profile: second\n")
+			parser := NewBiomeFileParser()
+
+			// Act
+			biome, err := parser.FindBiome("dup", []string{first, second})
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, "first", biome.AwsProfile)
+		})
+
+		t.Run("should report circular includes", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			a := writeFile(t, filepath.Join(dir, "a.yaml"), "include: b.yaml\n")
+			b := writeFile(t, filepath.Join(dir, "b.yaml"), "include: a.yaml\n")
+			parser := NewBiomeFileParser()
+
+			// Act
+			_, err := parser.FindBiome("any", []string{a})
+
+			// Assert
+			assert.ErrorContains(t, err, "circular include found")
+			assert.ErrorContains(t, err, a+" -> "+b+" -> "+a)
+		})
+
+		t.Run("should report a missing included file", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			main := writeFile(t, filepath.Join(dir, "main.yaml"), "include: nope.yaml\n")
+			parser := NewBiomeFileParser()
+
+			// Act
+			_, err := parser.FindBiome("any", []string{main})
+
+			// Assert
+			assert.ErrorContains(t, err, "nope.yaml")
+		})
+
+		t.Run("should list every file searched when the biome is missing", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			missing := filepath.Join(dir, "missing.yaml")
+			main := writeFile(t, filepath.Join(dir, "main.yaml"), "include: other.yaml\n")
+			other := writeFile(t, filepath.Join(dir, "other.yaml"), "name: other\n")
+			parser := NewBiomeFileParser()
+
+			// Act
+			_, err := parser.FindBiome("ghost", []string{missing, main})
+
+			// Assert
+			assert.ErrorContains(t, err, "unable to locate the 'ghost' biome")
+			assert.ErrorContains(t, err, strings.Join([]string{missing, main, other}, ", "))
+		})
+
+		t.Run("should merge the local override file on top of the config file", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			project := writeFile(t, filepath.Join(dir, ".biome.yaml"), `
+name: project
+inherit_from: shared
+environment:
+  PROJECT: p
+  DEBUG: "false"
+---
+name: shared
+aws_profile: team
+environment:
+  SHARED: s
+`)
+			local := writeFile(t, filepath.Join(dir, ".biome.local.yaml"), `
+name: project
+environment:
+  DEBUG: "true"
+  MINE: m
+---
+name: shared
+aws_profile: personal
+`)
+			parser := NewBiomeFileParser()
+
+			// Act
+			biome, err := parser.FindBiome("project", []string{project})
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, "personal", biome.AwsProfile)
+			assert.Equal(t, local, biome.ProfileFile)
+			assert.Equal(t, []string{"PROJECT", "DEBUG", "MINE", "SHARED"}, biome.EnvKeys())
+			assert.Equal(t, "true", biome.Environment["DEBUG"])
+			assert.Equal(t, types.EnvSource{Biome: "project", File: local, Line: 4, Local: true}, biome.EnvSource("DEBUG"))
+			assert.Equal(t, types.EnvSource{Biome: "project", File: local, Line: 5, Local: true}, biome.EnvSource("MINE"))
+			assert.Equal(t, types.EnvSource{Biome: "project", File: project, Line: 5}, biome.EnvSource("PROJECT"))
+		})
+
+		t.Run("should prefer the local override next to the first config file", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			project := writeFile(t, filepath.Join(dir, "project", ".biome.yml"), "name: project\naws_profile: team\n")
+			writeFile(t, filepath.Join(dir, "project", ".biome.local.yaml"), "name: project\naws_profile: project-local\n")
+			home := writeFile(t, filepath.Join(dir, "home", ".biome.yaml"), "name: other\n")
+			writeFile(t, filepath.Join(dir, "home", ".biome.local.yaml"), "name: project\naws_profile: home-local\n")
+			parser := NewBiomeFileParser()
+
+			// Act
+			biome, err := parser.FindBiome("project", []string{project, home})
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, "project-local", biome.AwsProfile)
+		})
+
+		t.Run("should report keys that can't be set in a local override file", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			project := writeFile(t, filepath.Join(dir, ".biome.yaml"), "name: project\n")
+			local := writeFile(t, filepath.Join(dir, ".biome.local.yaml"), "name: project\ncommands:\n  - rm -rf /\n---\nenvironment:\n  A: a\n")
+			parser := NewBiomeFileParser()
+
+			// Act
+			_, err := parser.FindBiome("project", []string{project})
+
+			// Assert
+			assert.ErrorContains(t, err, local+":2:1: 'commands' can't be set in a local override file")
+			assert.ErrorContains(t, err, local+":5:1: a local override needs the name of the biome it overrides")
+		})
+
+		t.Run("should report a local override for an unknown biome in strict mode", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			project := writeFile(t, filepath.Join(dir, ".biome.yaml"), "name: project\n")
+			local := writeFile(t, filepath.Join(dir, ".biome.local.yaml"), "name: renamed\naws_profile: mine\n")
+
+			// Act
+			_, lenientErr := NewBiomeFileParser().FindBiome("project", []string{project})
+			_, strictErr := NewStrictBiomeFileParser(nil).FindBiome("project", []string{project})
+
+			// Assert
+			assert.Nil(t, lenientErr)
+			assert.ErrorContains(t, strictErr, local+":1: local override for unknown biome 'renamed'")
+		})
+	})
+
+	t.Run("LocalConfigPaths", func(t *testing.T) {
+		t.Run("should look for the local override file with either extension", func(t *testing.T) {
+			// Act
+			paths := LocalConfigPaths("/project/.biome.yml")
+
+			// Assert
+			assert.Equal(t, []string{"/project/.biome.local.yml", "/project/.biome.local.yaml"}, paths)
+			assert.Nil(t, LocalConfigPaths("/project/.biome.local.yaml"))
+		})
+	})
+
+	t.Run("LoadBiomes", func(t *testing.T) {
+		t.Run("should load every biome in order without applying inheritance", func(t *testing.T) {
+			// Assemble
+			dir := t.TempDir()
+			first := filepath.Join(dir, "first.yaml")
+			second := filepath.Join(dir, "second.yaml")
+			assert.Nil(t, os.WriteFile(first, []byte("name: b\ninherit_from: a\n---\nname: a\naws_profile: first\n"), 0644))
+			assert.Nil(t, os.WriteFile(second, []byte("name: a\naws_profile: second\n---\nname: c\n"), 0644))
+			parser := NewBiomeFileParser()
+
+			// Act
+			biomes, err := parser.LoadBiomes([]string{first, second})
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Len(t, biomes, 3)
+			assert.Equal(t, "b", biomes[0].Name)
+			assert.Empty(t, biomes[0].AwsProfile)
+			assert.Equal(t, 1, biomes[0].SourceLine)
+			assert.Equal(t, "first", biomes[1].AwsProfile)
+			assert.Equal(t, 4, biomes[1].SourceLine)
+			assert.Equal(t, "c", biomes[2].Name)
+			assert.Equal(t, second, biomes[2].SourceFile)
+		})
+	})
+}
@@ -0,0 +1,500 @@
+package repos
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError is a problem found in a config file at a specific location
+type ConfigError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e ConfigError) Error() string {
+	switch {
+	case e.Line == 0:
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	case e.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	default:
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	}
+}
+
+// ConfigErrors is every problem found while parsing the config files
+type ConfigErrors []ConfigError
+
+func (e ConfigErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, "  "+err.Error())
+	}
+
+	return fmt.Sprintf("invalid biome configuration:\n%s", strings.Join(msgs, "\n"))
+}
+
+var yamlErrorLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// newConfigError will build a ConfigError at the position of the node
+func newConfigError(node *yaml.Node, format string, args ...interface{}) ConfigError {
+	return ConfigError{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// newYamlConfigError will convert an error from the yaml package into a ConfigError
+// using the line number in the message when there is no node to point at
+func newYamlConfigError(node *yaml.Node, err error) []ConfigError {
+	var msgs []string
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		msgs = typeErr.Errors
+	} else {
+		msgs = []string{err.Error()}
+	}
+
+	errs := make([]ConfigError, 0, len(msgs))
+	for _, msg := range msgs {
+		cfgErr := ConfigError{Message: yamlErrorLine.ReplaceAllString(msg, "")}
+
+		if node != nil {
+			cfgErr.Line = node.Line
+			cfgErr.Column = node.Column
+		} else if match := yamlErrorLine.FindStringSubmatch(msg); match != nil {
+			cfgErr.Line, _ = strconv.Atoi(match[1])
+		}
+
+		errs = append(errs, cfgErr)
+	}
+
+	return errs
+}
+
+// biomeValidator checks biome documents against the config types and the setter definitions
+type biomeValidator struct {
+	setterDefinitions []types.SetterDefinition
+}
+
+// validateDocument will report every problem found in a biome document
+func (v biomeValidator) validateDocument(doc *yaml.Node) []ConfigError {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return []ConfigError{newConfigError(root, "expected a biome document but found a %s", describeNode(root))}
+	}
+
+	errs := v.validateStruct(root, reflect.TypeOf(types.BiomeConfig{}))
+
+	if getMappingValue(root, "name") == nil && getMappingValue(root, "include") == nil {
+		errs = append(errs, newConfigError(root, "biome document is missing a 'name'"))
+	}
+
+	return errs
+}
+
+// validateStruct will check the mapping node against the yaml fields of the struct type
+func (v biomeValidator) validateStruct(node *yaml.Node, t reflect.Type) []ConfigError {
+	fields := getYamlFields(t)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	errs := checkDuplicateKeys(node)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valNode := node.Content[i], node.Content[i+1]
+
+		field, exists := fields[keyNode.Value]
+		if !exists {
+			errs = append(errs, newConfigError(keyNode, "unknown field '%s'%s", keyNode.Value, suggest(keyNode.Value, names)))
+			continue
+		}
+
+		switch {
+		case field.Type == reflect.TypeOf(map[string]interface{}{}):
+			errs = append(errs, v.validateEnvironment(valNode)...)
+		case field.Type == reflect.TypeOf(types.EnvFileList{}):
+			errs = append(errs, v.validateEnvFiles(valNode)...)
+		case field.Type.Kind() == reflect.Struct && !reflect.PtrTo(field.Type).Implements(reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()):
+			if valNode.Kind != yaml.MappingNode {
+				errs = append(errs, newConfigError(valNode, "'%s' must be a mapping but found a %s", keyNode.Value, describeNode(valNode)))
+				continue
+			}
+
+			errs = append(errs, v.validateStruct(valNode, field.Type)...)
+		case field.Type.Kind() == reflect.Map && field.Type.Elem().Kind() == reflect.Struct:
+			errs = append(errs, v.validateStructMap(keyNode.Value, valNode, field.Type.Elem())...)
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			errs = append(errs, v.validateStructList(keyNode.Value, valNode, field.Type.Elem())...)
+		default:
+			if err := valNode.Decode(reflect.New(field.Type).Interface()); err != nil {
+				errs = append(errs, newYamlConfigError(valNode, err)...)
+				continue
+			}
+
+			if enum := field.Tag.Get("enum"); enum != "" && !types.Contains(strings.Split(enum, ","), valNode.Value) {
+				errs = append(errs, newConfigError(valNode, "'%s' must be one of %s but found %s", keyNode.Value, enum, describeNode(valNode)))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateStructMap will check every entry of a block (such as params) where each value is a struct
+func (v biomeValidator) validateStructMap(name string, node *yaml.Node, t reflect.Type) []ConfigError {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return []ConfigError{newConfigError(node, "'%s' must be a mapping but found a %s", name, describeNode(node))}
+	}
+
+	errs := checkDuplicateKeys(node)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valNode := node.Content[i], node.Content[i+1]
+
+		switch {
+		case valNode.Kind == yaml.ScalarNode && valNode.Tag == "!!null":
+		case valNode.Kind != yaml.MappingNode:
+			errs = append(errs, newConfigError(valNode, "'%s' in '%s' must be a mapping but found a %s", keyNode.Value, name, describeNode(valNode)))
+		default:
+			errs = append(errs, v.validateStruct(valNode, t)...)
+		}
+	}
+
+	return errs
+}
+
+// validateStructList will check every item of a list (such as sources) where each item is a struct
+func (v biomeValidator) validateStructList(name string, node *yaml.Node, t reflect.Type) []ConfigError {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+
+	if node.Kind != yaml.SequenceNode {
+		return []ConfigError{newConfigError(node, "'%s' must be a list but found a %s", name, describeNode(node))}
+	}
+
+	var errs []ConfigError
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			errs = append(errs, newConfigError(item, "an item of '%s' must be a mapping but found a %s", name, describeNode(item)))
+			continue
+		}
+
+		itemErrs := v.validateStruct(item, t)
+
+		// Run any checks the type makes when it is decoded (ex: a source needs one kind)
+		if len(itemErrs) == 0 {
+			if err := item.Decode(reflect.New(t).Interface()); err != nil {
+				itemErrs = newYamlConfigError(item, err)
+			}
+		}
+
+		errs = append(errs, itemErrs...)
+	}
+
+	return errs
+}
+
+// validateEnvFiles will check load_env, which is a path or a list of paths and env files
+func (v biomeValidator) validateEnvFiles(node *yaml.Node) []ConfigError {
+	if node.Kind != yaml.SequenceNode {
+		if err := node.Decode(&types.EnvFileList{}); err != nil {
+			return newYamlConfigError(node, err)
+		}
+
+		return nil
+	}
+
+	var errs []ConfigError
+	for _, item := range node.Content {
+		switch item.Kind {
+		case yaml.ScalarNode:
+		case yaml.MappingNode:
+			itemErrs := v.validateStruct(item, reflect.TypeOf(types.EnvFile{}))
+			if len(itemErrs) == 0 && getMappingValue(item, "path") == nil {
+				itemErrs = append(itemErrs, newConfigError(item, "an env file needs a 'path'"))
+			}
+
+			errs = append(errs, itemErrs...)
+		default:
+			errs = append(errs, newConfigError(item, "an env file must be a path or a mapping but found a %s", describeNode(item)))
+		}
+	}
+
+	return errs
+}
+
+// validateEnvironment will check every entry of an environment block
+func (v biomeValidator) validateEnvironment(node *yaml.Node) []ConfigError {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return []ConfigError{newConfigError(node, "'environment' must be a mapping but found a %s", describeNode(node))}
+	}
+
+	errs := checkDuplicateKeys(node)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valNode := node.Content[i], node.Content[i+1]
+
+		switch valNode.Kind {
+		case yaml.ScalarNode:
+			if valNode.Tag == "!!null" {
+				errs = append(errs, newConfigError(valNode, "variable '%s' has no value", keyNode.Value))
+			}
+		case yaml.MappingNode:
+			errs = append(errs, v.validateSetter(keyNode.Value, valNode)...)
+		case yaml.SequenceNode:
+			errs = append(errs, newConfigError(valNode, "variable '%s' is a list, use 'value' with 'encode' to encode it", keyNode.Value))
+		}
+	}
+
+	return errs
+}
+
+// validateSetter will check a complex environment config against the setter definitions
+func (v biomeValidator) validateSetter(env string, node *yaml.Node) []ConfigError {
+	var selected []types.SetterDefinition
+	var selectors []string
+
+	for _, def := range v.setterDefinitions {
+		selectors = append(selectors, def.Selector.Name)
+
+		if getMappingValue(node, def.Selector.Name) != nil {
+			selected = append(selected, def)
+		}
+	}
+
+	switch len(selected) {
+	case 0:
+		return []ConfigError{newConfigError(node, "unknown environment config for variable '%s', expected one of: %s", env, strings.Join(selectors, ", "))}
+	case 1:
+	default:
+		return []ConfigError{newConfigError(node, "variable '%s' uses conflicting setters '%s' and '%s'", env, selected[0].Selector.Name, selected[1].Selector.Name)}
+	}
+
+	def := selected[0]
+	keys := map[string]types.SetterKey{def.Selector.Name: def.Selector}
+	names := []string{def.Selector.Name}
+	for _, key := range def.Keys {
+		keys[key.Name] = key
+		names = append(names, key.Name)
+	}
+
+	errs := checkDuplicateKeys(node)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valNode := node.Content[i], node.Content[i+1]
+
+		key, exists := keys[keyNode.Value]
+		if !exists {
+			errs = append(errs, newConfigError(keyNode, "unknown key '%s' for the '%s' setter of variable '%s'%s", keyNode.Value, def.Selector.Name, env, suggest(keyNode.Value, names)))
+			continue
+		}
+
+		if msg := checkSetterValue(key, valNode); msg != "" {
+			errs = append(errs, newConfigError(valNode, "'%s' for variable '%s' %s", key.Name, env, msg))
+		}
+	}
+
+	for _, key := range def.Keys {
+		if key.Required && getMappingValue(node, key.Name) == nil {
+			errs = append(errs, newConfigError(node, "variable '%s' is missing '%s', which is required with '%s'", env, key.Name, def.Selector.Name))
+		}
+	}
+
+	if len(def.ExactlyOne) > 0 {
+		set := 0
+		for _, name := range def.ExactlyOne {
+			if val := getMappingValue(node, name); val != nil && !(val.Tag == "!!bool" && val.Value == "false") {
+				set++
+			}
+		}
+
+		if set != 1 {
+			errs = append(errs, newConfigError(node, "variable '%s' needs exactly one of %s with '%s'", env, quoteNames(def.ExactlyOne), def.Selector.Name))
+		}
+	}
+
+	return errs
+}
+
+// quoteNames will list the names for error messages ('a', 'b' or 'c')
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("'%s'", name)
+	}
+
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
+	}
+
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+// checkSetterValue will return a description of the problem if the node is the wrong type for the key
+func checkSetterValue(key types.SetterKey, node *yaml.Node) string {
+	switch key.Type {
+	case types.SETTER_VALUE_BOOL:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			return fmt.Sprintf("must be true or false but found %s", describeNode(node))
+		}
+	case types.SETTER_VALUE_STRING:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+			return fmt.Sprintf("must be a string but found %s", describeNode(node))
+		}
+	case types.SETTER_VALUE_SCALAR:
+		if node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+			return fmt.Sprintf("must be a string, number or boolean but found %s", describeNode(node))
+		}
+	case types.SETTER_VALUE_ANY:
+		if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+			return fmt.Sprintf("must have a value but found %s", describeNode(node))
+		}
+	}
+
+	if len(key.Enum) > 0 && !types.Contains(key.Enum, node.Value) {
+		return fmt.Sprintf("must be one of %s but found %s", strings.Join(key.Enum, ","), describeNode(node))
+	}
+
+	if key.Const != nil && node.Value != fmt.Sprint(key.Const) {
+		return fmt.Sprintf("must be %v but found %s", key.Const, describeNode(node))
+	}
+
+	return ""
+}
+
+// checkDuplicateKeys will report any key that is defined more than once in the mapping
+func checkDuplicateKeys(node *yaml.Node) []ConfigError {
+	var errs []ConfigError
+	seen := make(map[string]*yaml.Node)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+
+		if first, exists := seen[keyNode.Value]; exists {
+			errs = append(errs, newConfigError(keyNode, "'%s' is already defined at line %d", keyNode.Value, first.Line))
+			continue
+		}
+
+		seen[keyNode.Value] = keyNode
+	}
+
+	return errs
+}
+
+// getYamlFields will map the yaml key of each field in the struct type to the field
+func getYamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		fields[name] = field
+	}
+
+	return fields
+}
+
+// describeNode will describe the kind of value in the node for error messages
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!null":
+			return "null"
+		case "!!str":
+			return fmt.Sprintf("string \"%s\"", node.Value)
+		default:
+			return fmt.Sprintf("%s %s", strings.TrimPrefix(node.Tag, "!!"), node.Value)
+		}
+	default:
+		return "value"
+	}
+}
+
+// suggest will return a hint with the closest candidate if the name looks like a typo of it
+func suggest(name string, candidates []string) string {
+	best, bestDist := "", 3
+	for _, candidate := range candidates {
+		if dist := levenshtein(name, candidate); dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+
+	if best == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(", did you mean '%s'?", best)
+}
+
+func levenshtein(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,267 @@
+package repos
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictBiomeFileParser(t *testing.T) {
+	setterDefinitions := []types.SetterDefinition{
+		{
+			Selector: types.SetterKey{Name: "secret_arn", Type: types.SETTER_VALUE_STRING},
+			Keys: []types.SetterKey{
+				{Name: "secret_json_key", Type: types.SETTER_VALUE_STRING},
+				{Name: "secret_whole", Type: types.SETTER_VALUE_BOOL},
+			},
+			ExactlyOne: []string{"secret_json_key", "secret_whole"},
+		},
+		{
+			Selector: types.SetterKey{Name: "from_cli", Type: types.SETTER_VALUE_BOOL, Const: true},
+			Keys:     []types.SetterKey{{Name: "is_secret", Type: types.SETTER_VALUE_BOOL}},
+		},
+		{
+			Selector: types.SetterKey{Name: "value", Type: types.SETTER_VALUE_ANY},
+			Keys: []types.SetterKey{
+				{Name: "mode", Type: types.SETTER_VALUE_STRING, Enum: types.EnvModes},
+				{Name: "separator", Type: types.SETTER_VALUE_STRING},
+				{Name: "encode", Type: types.SETTER_VALUE_STRING, Enum: []string{"json", "yaml", "csv", "join"}},
+			},
+		},
+	}
+
+	// Helper for parsing the contents and getting the error messages
+	getErrors := func(contents string) []string {
+		docs := NewStrictBiomeFileParser(setterDefinitions).parseDocuments(strings.NewReader(contents))
+
+		msgs := make([]string, 0, len(docs.errors))
+		for _, err := range docs.errors {
+			err.File = "test.yaml"
+			msgs = append(msgs, err.Error())
+		}
+
+		return msgs
+	}
+
+	t.Run("should accept a valid config", func(t *testing.T) {
+		errs := getErrors(`
+name: valid
+aws_profile: profile
+inherit_from: [base]
+load_env:
+  - .env
+  - path: .env.local
+    optional: true
+  - path: vars.txt
+    format: yaml
+sources:
+  - secret_arn: prod/app
+    prefix: APP_
+    case: upper
+    include: [db_*]
+    rename:
+      pw: DB_PASSWORD
+  - ssm_path: /app/prod
+    ssm_decrypt: true
+merge:
+  commands: replace
+params:
+  service:
+  region:
+    description: The region to deploy to
+    default: us-east-1
+    allowed: [us-east-1, us-west-2]
+environment:
+  BASIC: value
+  NUMBER: 5
+  SECRET:
+    secret_arn: arn
+    secret_json_key: key
+  PROMPT:
+    from_cli: true
+    is_secret: false
+  PATH:
+    value: ./bin
+    mode: prepend
+    separator: ":"
+  LOG_LEVEL:
+    value: 5
+    mode: default
+  HOSTS:
+    value: [a, b]
+    encode: csv
+---
+include: other.yaml
+`)
+
+		assert.Empty(t, errs)
+	})
+
+	t.Run("should report unknown fields with a suggestion", func(t *testing.T) {
+		errs := getErrors("name: typo\nenviroment:\n  A: a\n")
+
+		assert.Equal(t, []string{"test.yaml:2:1: unknown field 'enviroment', did you mean 'environment'?"}, errs)
+	})
+
+	t.Run("should report unknown fields in nested settings", func(t *testing.T) {
+		errs := getErrors("name: typo\nmerge:\n  command: replace\n")
+
+		assert.Equal(t, []string{"test.yaml:3:3: unknown field 'command', did you mean 'commands'?"}, errs)
+	})
+
+	t.Run("should report unknown fields in params", func(t *testing.T) {
+		errs := getErrors("name: typo\nparams:\n  region:\n    defualt: us-east-1\n  service: billing\n")
+
+		assert.Equal(t, []string{
+			"test.yaml:4:5: unknown field 'defualt', did you mean 'default'?",
+			`test.yaml:5:12: 'service' in 'params' must be a mapping but found a string "billing"`,
+		}, errs)
+	})
+
+	t.Run("should report problems with env files", func(t *testing.T) {
+		errs := getErrors("name: files\nload_env:\n  - path: .env\n    optinal: true\n  - optional: true\n  - path: vars\n    format: toml\n  - [a]\n")
+
+		assert.Equal(t, []string{
+			"test.yaml:4:5: unknown field 'optinal', did you mean 'optional'?",
+			"test.yaml:5:5: an env file needs a 'path'",
+			`test.yaml:7:13: 'format' must be one of dotenv,json,yaml but found string "toml"`,
+			"test.yaml:8:5: an env file must be a path or a mapping but found a list",
+		}, errs)
+	})
+
+	t.Run("should report problems with sources", func(t *testing.T) {
+		errs := getErrors("name: sources\nsources:\n  - secret_arn: a\n    prefx: APP_\n  - prefix: APP_\n  - file: a.env\n    case: title\n  - a.env\n")
+
+		assert.Equal(t, []string{
+			"test.yaml:4:5: unknown field 'prefx', did you mean 'prefix'?",
+			"test.yaml:5:5: a source needs exactly one of 'secret_arn', 'ssm_path' or 'file'",
+			`test.yaml:7:11: 'case' must be one of upper,lower but found string "title"`,
+			`test.yaml:8:5: an item of 'sources' must be a mapping but found a string "a.env"`,
+		}, errs)
+	})
+
+	t.Run("should report type mismatches", func(t *testing.T) {
+		errs := getErrors("name: types\ncommands: not-a-list\n")
+
+		assert.Len(t, errs, 1)
+		assert.Contains(t, errs[0], "test.yaml:2:11: cannot unmarshal")
+	})
+
+	t.Run("should report values that are not allowed", func(t *testing.T) {
+		errs := getErrors("name: enum\nmerge:\n  commands: shuffle\n")
+
+		assert.Equal(t, []string{`test.yaml:3:13: 'commands' must be one of prepend,append,replace but found string "shuffle"`}, errs)
+	})
+
+	t.Run("should report setter values with the wrong type", func(t *testing.T) {
+		errs := getErrors(`
+name: setter
+environment:
+  PROMPT:
+    from_cli: "yes"
+`)
+
+		assert.Equal(t, []string{`test.yaml:5:15: 'from_cli' for variable 'PROMPT' must be true or false but found string "yes"`}, errs)
+	})
+
+	t.Run("should report malformed setter maps", func(t *testing.T) {
+		errs := getErrors(`
+name: setter
+environment:
+  MISSING_KEY:
+    secret_arn: arn
+  UNKNOWN:
+    from_nowhere: true
+  CONFLICT:
+    secret_arn: arn
+    secret_json_key: key
+    from_cli: true
+  EXTRA:
+    from_cli: true
+    is_secrett: true
+  EMPTY:
+`)
+
+		assert.Equal(t, []string{
+			"test.yaml:5:5: variable 'MISSING_KEY' needs exactly one of 'secret_json_key' or 'secret_whole' with 'secret_arn'",
+			"test.yaml:7:5: unknown environment config for variable 'UNKNOWN', expected one of: secret_arn, from_cli, value",
+			"test.yaml:9:5: variable 'CONFLICT' uses conflicting setters 'secret_arn' and 'from_cli'",
+			"test.yaml:14:5: unknown key 'is_secrett' for the 'from_cli' setter of variable 'EXTRA', did you mean 'is_secret'?",
+			"test.yaml:15:9: variable 'EMPTY' has no value",
+		}, errs)
+	})
+
+	t.Run("should report setters that can't be activated", func(t *testing.T) {
+		errs := getErrors(`
+name: setter
+environment:
+  WHOLE:
+    secret_arn: arn
+    secret_whole: true
+  KEY_NOT_WHOLE:
+    secret_arn: arn
+    secret_json_key: key
+    secret_whole: false
+  BOTH:
+    secret_arn: arn
+    secret_json_key: key
+    secret_whole: true
+  NOT_WHOLE:
+    secret_arn: arn
+    secret_whole: false
+  NO_PROMPT:
+    from_cli: false
+`)
+
+		assert.Equal(t, []string{
+			"test.yaml:12:5: variable 'BOTH' needs exactly one of 'secret_json_key' or 'secret_whole' with 'secret_arn'",
+			"test.yaml:16:5: variable 'NOT_WHOLE' needs exactly one of 'secret_json_key' or 'secret_whole' with 'secret_arn'",
+			"test.yaml:19:15: 'from_cli' for variable 'NO_PROMPT' must be true but found bool false",
+		}, errs)
+	})
+
+	t.Run("should report lists without an encoding", func(t *testing.T) {
+		errs := getErrors("name: lists\nenvironment:\n  HOSTS: [a, b]\n")
+
+		assert.Equal(t, []string{"test.yaml:3:10: variable 'HOSTS' is a list, use 'value' with 'encode' to encode it"}, errs)
+	})
+
+	t.Run("should report duplicate keys and biome names", func(t *testing.T) {
+		errs := getErrors(`
+name: dup
+environment:
+  A: a
+  A: b
+---
+name: dup
+`)
+
+		assert.Equal(t, []string{
+			"test.yaml:5:3: 'A' is already defined at line 4",
+			"test.yaml:7:7: duplicate biome name 'dup', first defined at line 2",
+		}, errs)
+	})
+
+	t.Run("should report documents without a name", func(t *testing.T) {
+		errs := getErrors("aws_profile: nameless\n")
+
+		assert.Equal(t, []string{"test.yaml:1:1: biome document is missing a 'name'"}, errs)
+	})
+
+	t.Run("should report syntax errors", func(t *testing.T) {
+		errs := getErrors("name: broken\ncommands: [a\n")
+
+		assert.Len(t, errs, 1)
+		assert.Contains(t, errs[0], "test.yaml:")
+		assert.NotContains(t, errs[0], "yaml: line")
+	})
+
+	t.Run("should not report errors when not strict", func(t *testing.T) {
+		docs := NewBiomeFileParser().parseDocuments(strings.NewReader("name: lenient\nenviroment:\n  A: a\n---\nname: [broken\n"))
+
+		assert.Empty(t, docs.errors)
+		assert.Len(t, docs.biomes, 1)
+	})
+}
@@ -9,18 +9,52 @@ type MockBiomeFileParser struct {
 	mock.Mock
 }
 
-func (m MockBiomeFileParser) FindBiome(biomeName string, searchFiles []string) (*types.BiomeConfig, error) {
+func (m *MockBiomeFileParser) FindBiome(biomeName string, searchFiles []string) (*types.BiomeConfig, error) {
 	args := m.Called(biomeName, searchFiles)
 	return args.Get(0).(*types.BiomeConfig), args.Error(1)
 }
 
+func (m *MockBiomeFileParser) LoadBiomes(searchFiles []string) ([]*types.BiomeConfig, error) {
+	args := m.Called(searchFiles)
+	return args.Get(0).([]*types.BiomeConfig), args.Error(1)
+}
+
 type MockAwsStsRepository struct {
 	mock.Mock
 }
 
-func (m MockAwsStsRepository) ConfigureSession(profile string) (*types.AwsEnvConfig, error) {
+func (m *MockAwsStsRepository) ConfigureSession(profile string) (*types.AwsEnvConfig, error) {
 	args := m.Called(profile)
 	return args.Get(0).(*types.AwsEnvConfig), args.Error(1)
 }
 
-func (m MockAwsStsRepository) SetAwsEnvs(cfg *types.AwsEnvConfig) {}
+func (m *MockAwsStsRepository) SetAwsEnvs(cfg *types.AwsEnvConfig) {}
+
+type MockSecretsManager struct {
+	mock.Mock
+}
+
+func (m *MockSecretsManager) GetSecretString(arn string) (string, error) {
+	args := m.Called(arn)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSecretsManager) GetSecret(arn string, version SecretVersion) (*SecretValue, error) {
+	args := m.Called(arn, version)
+	secret, _ := args.Get(0).(*SecretValue)
+	return secret, args.Error(1)
+}
+
+type MockParameterStore struct {
+	mock.Mock
+}
+
+func (m *MockParameterStore) GetParameters(names []string, decrypt bool) (map[string]SsmParameter, error) {
+	args := m.Called(names, decrypt)
+	return args.Get(0).(map[string]SsmParameter), args.Error(1)
+}
+
+func (m *MockParameterStore) GetParametersByPath(path string, decrypt bool) ([]SsmParameter, error) {
+	args := m.Called(path, decrypt)
+	return args.Get(0).([]SsmParameter), args.Error(1)
+}
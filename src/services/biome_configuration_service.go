@@ -1,53 +1,90 @@
 package services
 
 import (
+	"bufio"
 	"fmt"
-	"path"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/jeff-roche/biome/src/lib/cmdr"
+	"github.com/jeff-roche/biome/src/lib/envfile"
 	"github.com/jeff-roche/biome/src/lib/fileio"
+	"github.com/jeff-roche/biome/src/lib/interpolation"
 	"github.com/jeff-roche/biome/src/lib/setters"
 	"github.com/jeff-roche/biome/src/lib/types"
 	"github.com/jeff-roche/biome/src/repos"
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
-var defaultFileNames = []string{".biome.yaml", ".biome.yml"}
-
 // BiomeConfigurationService handles the loading and activation of biomes
 type BiomeConfigurationService struct {
 	ActiveBiome    *types.BiomeConfig
 	configFileRepo repos.BiomeFileParserIfc
 	awsStsRepo     repos.AwsStsRepositoryIfc
 	configuredEnvs map[string]string
+	configFiles    []string          // Config files that replace the default search paths
+	paramArgs      map[string]string // Param values supplied on the command line
+	params         map[string]string // The resolved params of the active biome
+	paramPrompt    types.ParamPrompt // Asks for missing params, nil when not interactive
+	cleanEnv       bool              // Isolate the biome from the host environment
+	warnings       io.Writer         // Where warnings (ex: deprecated variables) are written, nil to ignore them
+	stdin          io.Reader         // Where the CLI setters read their values from, os.Stdin when nil
+
+	// Repositories for the sources in AWS, built when they are first used
+	secretsManagerRepo func() (repos.SecretsManagerIfc, error)
+	parameterStoreRepo func() (repos.ParameterStoreIfc, error)
 }
 
 // NewBiomeConfigurationService is a builder function to generate the service
 func NewBiomeConfigurationService() *BiomeConfigurationService {
-	return &BiomeConfigurationService{
+	svc := &BiomeConfigurationService{
 		configFileRepo: repos.NewBiomeFileParser(),
 		awsStsRepo:     repos.NewAwsStsRepository(),
 		configuredEnvs: make(map[string]string),
+		warnings:       os.Stderr,
+
+		secretsManagerRepo: newSecretsManagerRepo,
+		parameterStoreRepo: newParameterStoreRepo,
 	}
+
+	// Only prompt for missing params when someone is there to answer
+	if terminal.IsTerminal(int(os.Stdin.Fd())) {
+		svc.paramPrompt = promptForParam(bufio.NewReader(os.Stdin))
+	}
+
+	return svc
+}
+
+// SetConfigFiles will replace the default config search paths with the files specified
+func (svc *BiomeConfigurationService) SetConfigFiles(files []string) {
+	svc.configFiles = files
+}
+
+// SetParams will set the param values used when the biome is activated
+func (svc *BiomeConfigurationService) SetParams(params map[string]string) {
+	svc.paramArgs = params
 }
 
-// LoadBiome will search for the biome in the default locations
-//     - Current directory .biome.[yaml|yml]
-//     - Current user's home directory .biome.[yaml|yml]
+// Params will return the resolved params of the activated biome
+func (svc *BiomeConfigurationService) Params() map[string]string {
+	return svc.params
+}
+
+// EnableStrictParsing will validate the config files and report every problem found
+// instead of skipping the documents that can't be parsed
+func (svc *BiomeConfigurationService) EnableStrictParsing() {
+	svc.configFileRepo = repos.NewStrictBiomeFileParser(setters.SetterDefinitions)
+}
+
+// LoadBiomeFromDefaults will search for the biome in the config files returned by ConfigSearchPaths
 func (svc *BiomeConfigurationService) LoadBiomeFromDefaults(biomeName string) error {
-	// Setup the valid paths
 	var validPaths []string
-	if dir, err := fileio.GetCD(); err == nil {
-		for _, fname := range defaultFileNames {
-			validPaths = append(validPaths, path.Join(dir, fname))
-		}
-	}
-
-	if dir, err := fileio.GetHomeDir(); err == nil {
-		for _, fname := range defaultFileNames {
-			validPaths = append(validPaths, path.Join(dir, fname))
-		}
+	for _, searchPath := range svc.ConfigSearchPaths() {
+		validPaths = append(validPaths, searchPath.Path)
 	}
 
 	// Start blasting
@@ -86,29 +123,158 @@ func (svc *BiomeConfigurationService) ActivateBiome() error {
 		return fmt.Errorf("no biome loaded")
 	}
 
+	svc.warnDeprecated()
+
+	if err := svc.resolveEnvironment(); err != nil {
+		return err
+	}
+
+	// Additional Commands
+	if err := svc.runSetupCommands(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// resolveEnvironment will set every environment variable of the biome without running its commands
+//
+// Every prompt (params and CLI setters) is asked before the AWS session is configured and anything is fetched
+func (svc *BiomeConfigurationService) resolveEnvironment() error {
+	// Host environment
+	if err := svc.cleanHostEnv(); err != nil {
+		return err
+	}
+
+	// Params
+	if err := svc.loadParams(true); err != nil {
+		return err
+	}
+
+	// CLI Input
+	cli, err := svc.promptEnvs()
+	if err != nil {
+		return err
+	}
+
 	// AWS
 	if err := svc.loadAws(); err != nil {
 		return err
 	}
 
 	// Dot Env
-	if err := svc.loadFromEnv(svc.ActiveBiome.ExternalEnvFile); err != nil {
+	if err := svc.loadFromEnv(svc.ActiveBiome.EnvFiles); err != nil {
 		return err
 	}
 
-	// Parse all Envs
-	if err := svc.loadEnvs(); err != nil {
+	// Sources
+	if err := svc.loadSources(true); err != nil {
 		return err
 	}
 
-	// Additional Commands
-	if err := svc.runSetupCommands(); err != nil {
+	// Parse all Envs
+	return svc.loadEnvs(cli)
+}
+
+// promptEnvs will ask for the values of the CLI setters in the declared order
+func (svc *BiomeConfigurationService) promptEnvs() (*setters.CliInput, error) {
+	rd := svc.stdin
+	if rd == nil {
+		rd = os.Stdin
+	}
+
+	cli := setters.NewCliInput(rd)
+	for _, env := range svc.ActiveBiome.EnvKeys() {
+		if err := cli.Prompt(env, svc.ActiveBiome.Environment[env]); err != nil {
+			return nil, fmt.Errorf("error setting '%s': %v", env, err)
+		}
+	}
+
+	return cli, nil
+}
+
+// loadParams will resolve the biome's params, export them to the environment and
+// substitute them into the aws_profile, load_env, sources and commands of the biome
+//
+// Params are exported as BIOME_PARAM_<NAME> so the command being run can read them. Without
+// resolve nothing is prompted for, required params that weren't supplied are left unresolved
+func (svc *BiomeConfigurationService) loadParams(resolve bool) error {
+	var params map[string]string
+	var err error
+
+	if resolve {
+		params, err = svc.ActiveBiome.ResolveParams(svc.paramArgs, svc.paramPrompt)
+	} else {
+		params, err = svc.ActiveBiome.KnownParams(svc.paramArgs)
+	}
+
+	if err != nil {
 		return err
 	}
 
+	svc.params = params
+
+	for _, name := range svc.ActiveBiome.ParamKeys() {
+		val, exists := params[name]
+		if !exists {
+			continue
+		}
+
+		if err := os.Setenv(types.ParamEnvName(name), val); err != nil {
+			return err
+		}
+	}
+
+	// Only the declared params are substituted, anything else (${HOME} in a command) is left as is
+	lookup := func(name string) (string, bool) {
+		val, exists := params[name]
+		return val, exists
+	}
+
+	svc.ActiveBiome.AwsProfile = interpolation.ExpandKnown(svc.ActiveBiome.AwsProfile, lookup)
+
+	for i, file := range svc.ActiveBiome.EnvFiles {
+		svc.ActiveBiome.EnvFiles[i].Path = interpolation.ExpandKnown(file.Path, lookup)
+	}
+
+	for i, source := range svc.ActiveBiome.Sources {
+		for _, field := range []*string{&source.SecretArn, &source.SsmPath, &source.File} {
+			*field = interpolation.ExpandKnown(*field, lookup)
+		}
+
+		svc.ActiveBiome.Sources[i] = source
+	}
+
+	for i, cmd := range svc.ActiveBiome.Commands {
+		svc.ActiveBiome.Commands[i] = interpolation.ExpandKnown(cmd, lookup)
+	}
+
 	return nil
 }
 
+// promptForParam will build a prompt that reads the value of a param from the reader (stdin)
+func promptForParam(rd *bufio.Reader) types.ParamPrompt {
+	return func(name string, param types.ParamConfig) (string, error) {
+		prompt := name
+		if param.Description != "" {
+			prompt = fmt.Sprintf("%s (%s)", name, param.Description)
+		}
+
+		if len(param.Allowed) > 0 {
+			prompt = fmt.Sprintf("%s [%s]", prompt, strings.Join(param.Allowed, "|"))
+		}
+
+		fmt.Printf("%s: ", prompt)
+
+		val, err := rd.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimSpace(val), nil
+	}
+}
+
 // loadAws will load in the AWS profile if one was specified
 func (svc *BiomeConfigurationService) loadAws() error {
 	if svc.ActiveBiome.AwsProfile != "" {
@@ -123,21 +289,47 @@ func (svc *BiomeConfigurationService) loadAws() error {
 	return nil
 }
 
-// loadFromEnv will load in addition environment variables from the ENV file
-//     Any envs specified in the biome config will override vars specified in the dotenv
-func (svc *BiomeConfigurationService) loadFromEnv(fname string) error {
-	if fname != "" {
-		loadedEnvs, err := godotenv.Read(fname)
+// loadFromEnv will load in additional environment variables from the env files
+//     Files later in the list override the files before them, and any envs specified
+//     in the biome config override the files. Missing optional files are skipped
+func (svc *BiomeConfigurationService) loadFromEnv(files types.EnvFileList) error {
+	loadedEnvs := make(map[string]types.EnvSource)
+	values := make(map[string]string)
+
+	for _, file := range files {
+		fpath := file.ResolvedPath()
+		if !fileio.FileExists(fpath) {
+			if file.Optional {
+				continue
+			}
+
+			return fmt.Errorf("unable to load the env file '%s': the file does not exist", fpath)
+		}
+
+		vars, err := envfile.Read(fpath, file.Format)
 		if err != nil {
-			return err
+			return fmt.Errorf("unable to load the env file: %v", err)
+		}
+
+		for _, v := range vars {
+			values[v.Name] = v.Value
+			loadedEnvs[v.Name] = types.EnvSource{Biome: svc.ActiveBiome.Name, File: fpath, Line: v.Line, Dotenv: true}
 		}
+	}
+
+	keys := make([]string, 0, len(loadedEnvs))
+	for key := range loadedEnvs {
+		keys = append(keys, key)
+	}
 
-		for key, val := range loadedEnvs {
+	sort.Strings(keys)
 
-			// Only save the key if one wasn't specified in the biome config
-			if _, exists := svc.ActiveBiome.Environment[key]; !exists {
-				svc.ActiveBiome.Environment[key] = val
-			}
+	for _, key := range keys {
+
+		// Only save the key if one wasn't specified in the biome config
+		// env file values are taken literally so they are escaped from interpolation
+		if _, exists := svc.ActiveBiome.Environment[key]; !exists {
+			svc.ActiveBiome.AddEnvFrom(key, interpolation.Escape(values[key]), loadedEnvs[key])
 		}
 	}
 
@@ -145,19 +337,45 @@ func (svc *BiomeConfigurationService) loadFromEnv(fname string) error {
 }
 
 // loadEnvs will parse all the envs in the Environment map and load them into memory
-func (svc *BiomeConfigurationService) loadEnvs() error {
-	// Loop over the envs and set them
-	for env, val := range svc.ActiveBiome.Environment {
-		setter, err := setters.GetEnvironmentSetter(env, val)
+//
+// The CLI setters use the values already entered with promptEnvs.
+// Values can reference other envs or host variables with ${NAME} so the envs are set
+// in dependency order. A self reference (PATH: "./bin:${PATH}") refers to the host value
+func (svc *BiomeConfigurationService) loadEnvs(cli *setters.CliInput) error {
+	order, err := svc.getEnvResolutionOrder()
+	if err != nil {
+		return err
+	}
+
+	// Build the setters, Parameter Store parameters are loaded together when the first one is set
+	ssmBatch := setters.NewSsmParameterBatch(svc.parameterStoreRepo)
+	envSetters := make(map[string]setters.EnvironmentSetter)
+	for _, env := range svc.ActiveBiome.EnvKeys() {
+		// Files are relative to the config file the variable is declared in
+		var dir string
+		if source := svc.ActiveBiome.EnvSource(env); source.File != "" {
+			dir = filepath.Dir(source.File)
+		}
+
+		setter, err := setters.GetEnvironmentSetter(env, svc.ActiveBiome.Environment[env], setters.SetterOptions{Lookup: svc.lookupEnv(env), Dir: dir, Ssm: ssmBatch, Biome: svc.ActiveBiome.Name, Cli: cli})
 		if err != nil {
 			return fmt.Errorf("error setting '%s': %v", env, err)
 		}
 
-		raw_val, err := setter.SetEnv()
+		envSetters[env] = setter
+	}
+
+	// Loop over the envs and set them
+	for _, env := range order {
+		raw_val, err := envSetters[env].SetEnv()
 		if err != nil {
 			return fmt.Errorf("error setting '%s': %v", env, err)
 		}
 
+		if raw_val == "" && types.GetEnvMetadata(svc.ActiveBiome.Environment[env]).Required {
+			return fmt.Errorf("'%s' is required but its value is empty", env)
+		}
+
 		// Save off the envs we configured
 		svc.configuredEnvs[env] = raw_val
 	}
@@ -165,6 +383,59 @@ func (svc *BiomeConfigurationService) loadEnvs() error {
 	return nil
 }
 
+// warnDeprecated will warn about every deprecated variable the biome sets
+func (svc *BiomeConfigurationService) warnDeprecated() {
+	if svc.warnings == nil {
+		return
+	}
+
+	for _, env := range svc.ActiveBiome.EnvKeys() {
+		meta := types.GetEnvMetadata(svc.ActiveBiome.Environment[env])
+		if !meta.Deprecated {
+			continue
+		}
+
+		msg := fmt.Sprintf("warning: '%s' (from biome '%s') is deprecated", env, svc.ActiveBiome.EnvSource(env).Biome)
+		if meta.DeprecationMessage != "" {
+			msg += ": " + meta.DeprecationMessage
+		}
+
+		fmt.Fprintln(svc.warnings, msg)
+	}
+}
+
+// getEnvResolutionOrder will order the envs so that referenced envs are resolved first
+func (svc *BiomeConfigurationService) getEnvResolutionOrder() ([]string, error) {
+	keys := svc.ActiveBiome.EnvKeys()
+
+	deps := make(map[string][]string)
+	for _, env := range keys {
+		for _, ref := range setters.GetReferences(svc.ActiveBiome.Environment[env]) {
+			if _, exists := svc.ActiveBiome.Environment[ref]; exists && ref != env {
+				deps[env] = append(deps[env], ref)
+			}
+		}
+	}
+
+	return interpolation.Sort(keys, deps)
+}
+
+// lookupEnv will build the variable lookup used when expanding the value of env
+// Envs already configured by the biome take priority over the params, followed by the host environment
+func (svc *BiomeConfigurationService) lookupEnv(env string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		if val, exists := svc.configuredEnvs[name]; exists && name != env {
+			return val, true
+		}
+
+		if val, exists := svc.params[name]; exists {
+			return val, true
+		}
+
+		return os.LookupEnv(name)
+	}
+}
+
 // runSetupCommands will run any command line commands specified in the biome configuration
 func (svc *BiomeConfigurationService) runSetupCommands() error {
 	if len(svc.ActiveBiome.Commands) > 0 {
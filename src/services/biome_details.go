@@ -0,0 +1,250 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jeff-roche/biome/src/lib/interpolation"
+	"github.com/jeff-roche/biome/src/lib/setters"
+	"github.com/jeff-roche/biome/src/lib/types"
+)
+
+// REDACTED replaces the value of a secret unless it is revealed
+const REDACTED = "********"
+
+// DOTENV_SETTER is the setter reported for variables loaded from the load_env file
+const DOTENV_SETTER = "load_env"
+
+// ShowOptions controls how much of the biome is resolved when it is shown
+type ShowOptions struct {
+	Resolve bool // Run the setters (AWS calls, prompts) to show the values that would be set
+	Reveal  bool // Show the values of secrets instead of redacting them
+}
+
+// EnvDetails describes an environment variable of a biome and where it comes from
+type EnvDetails struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Resolved bool   `json:"resolved"` // The value was set by its setter, otherwise it is the config
+	Secret   bool   `json:"secret"`
+	Setter   string `json:"setter"` // The key that selects the setter (value, secret_arn, ...), load_env or sources
+	Mode     string `json:"mode,omitempty"`
+	Biome    string `json:"biome"` // The biome that declared the variable
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Local    bool   `json:"local"`          // Set by a local override file
+	From     string `json:"from,omitempty"` // The source that expanded into the variable
+}
+
+// BiomeDetails describes the fully merged configuration of a biome
+type BiomeDetails struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Tags        []string          `json:"tags"`
+	File        string            `json:"file"`
+	Line        int               `json:"line,omitempty"`
+	Parents     []string          `json:"parents"`
+	AwsProfile  string            `json:"aws_profile,omitempty"`
+	ProfileFile string            `json:"aws_profile_file,omitempty"` // The local override file that replaced the aws_profile
+	LoadEnv     []string          `json:"load_env"`                   // The env files in precedence order, lowest first
+	Sources     []string          `json:"sources"`                    // The sources in precedence order, lowest first
+	Commands    []string          `json:"commands"`
+	Params      map[string]string `json:"params"`
+	Unresolved  []string          `json:"unresolved_params,omitempty"` // Required params that weren't supplied, only without resolving
+	Unset       []string          `json:"unset"`
+	Isolate     bool              `json:"isolate"`
+	AllowEnv    []string          `json:"allow_env"`
+	Environment []EnvDetails      `json:"environment"`
+}
+
+// ShowBiome will describe the loaded biome after inheritance, with the source of every environment variable
+//
+// Without Resolve no setter is run so nothing is fetched from AWS and nothing is prompted for. The params
+// that were supplied or have a default are still resolved, the rest are reported as unresolved, and the
+// load_env and source files are still read so their variables can be shown
+func (svc *BiomeConfigurationService) ShowBiome(opts ShowOptions) (*BiomeDetails, error) {
+	if svc.ActiveBiome == nil {
+		return nil, fmt.Errorf("no biome loaded")
+	}
+
+	if opts.Resolve {
+		if err := svc.resolveEnvironment(); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := svc.loadParams(false); err != nil {
+			return nil, err
+		}
+
+		if err := svc.loadFromEnv(svc.ActiveBiome.EnvFiles); err != nil {
+			return nil, err
+		}
+
+		if err := svc.loadSources(false); err != nil {
+			return nil, err
+		}
+	}
+
+	biome := svc.ActiveBiome
+	details := &BiomeDetails{
+		Name:        biome.Name,
+		Description: biome.Description,
+		Tags:        append([]string{}, biome.Tags...),
+		File:        biome.SourceFile,
+		Line:        biome.SourceLine,
+		Parents:     append([]string{}, biome.Parents...),
+		AwsProfile:  biome.AwsProfile,
+		ProfileFile: biome.ProfileFile,
+		LoadEnv:     []string{},
+		Sources:     []string{},
+		Commands:    append([]string{}, biome.Commands...),
+		Params:      make(map[string]string, len(svc.params)),
+		Unset:       append([]string{}, biome.Unset...),
+		Isolate:     biome.IsIsolated() || svc.cleanEnv,
+		AllowEnv:    append([]string{}, biome.AllowEnv...),
+		Environment: []EnvDetails{},
+	}
+
+	for _, file := range biome.EnvFiles {
+		details.LoadEnv = append(details.LoadEnv, file.ResolvedPath())
+	}
+
+	for _, source := range biome.Sources {
+		details.Sources = append(details.Sources, source.String())
+	}
+
+	for name, val := range svc.params {
+		details.Params[name] = val
+	}
+
+	for _, name := range biome.ParamKeys() {
+		if _, exists := svc.params[name]; !exists {
+			details.Unresolved = append(details.Unresolved, name)
+		}
+	}
+
+	secrets := svc.secretEnvs()
+
+	for _, env := range biome.EnvKeys() {
+		config := biome.Environment[env]
+		source := biome.EnvSource(env)
+
+		envDetails := EnvDetails{
+			Name:     env,
+			Resolved: opts.Resolve,
+			Secret:   secrets[env],
+			Setter:   setters.SetterType(config),
+			Mode:     types.EnvMode(config),
+			Biome:    source.Biome,
+			File:     source.File,
+			Line:     source.Line,
+			Local:    source.Local,
+			From:     source.From,
+		}
+
+		// load_env and source values are escaped so they are taken literally
+		literal := source.Dotenv || source.From != ""
+
+		if source.Dotenv {
+			envDetails.Setter = DOTENV_SETTER
+		} else if source.From != "" {
+			envDetails.Setter = SOURCES_SETTER
+		}
+
+		var err error
+		if opts.Resolve {
+			envDetails.Value = svc.configuredEnvs[env]
+		} else if envDetails.Value, err = describeEnvConfig(config, literal); err != nil {
+			return nil, fmt.Errorf("unable to describe '%s': %v", env, err)
+		}
+
+		if envDetails.Secret && opts.Resolve && !opts.Reveal {
+			envDetails.Value = REDACTED
+		}
+
+		details.Environment = append(details.Environment, envDetails)
+	}
+
+	return details, nil
+}
+
+// secretEnvs will find the variables of the biome that hold a secret, either because their setter
+// sets one or because they read a secret through their references, directly or through other variables
+func (svc *BiomeConfigurationService) secretEnvs() map[string]bool {
+	biome := svc.ActiveBiome
+	secrets := make(map[string]bool, len(biome.Environment))
+
+	// 0 is unvisited, 1 is being checked (a reference cycle) and 2 is done
+	state := make(map[string]int, len(biome.Environment))
+
+	var check func(env string) bool
+	check = func(env string) bool {
+		if state[env] != 0 {
+			return secrets[env]
+		}
+
+		state[env] = 1
+		config := biome.Environment[env]
+		secret := setters.IsSecret(config) || biome.EnvSource(env).Secret
+
+		for _, ref := range setters.GetReferences(config) {
+			// A self reference reads the host value
+			if _, exists := biome.Environment[ref]; exists && ref != env && check(ref) {
+				secret = true
+			}
+		}
+
+		secrets[env] = secret
+		state[env] = 2
+
+		return secret
+	}
+
+	for _, env := range biome.EnvKeys() {
+		check(env)
+	}
+
+	return secrets
+}
+
+// describeEnvConfig will describe an environment config that hasn't been resolved
+//
+// Basic values are shown as they are and setters are shown as JSON without the mode keys,
+// which are shown on their own, or the metadata keys. The separator is kept when it joins an encoded value
+func describeEnvConfig(config interface{}, literal bool) (string, error) {
+	switch val := config.(type) {
+	case string:
+		// Literal values are escaped, expanding them gives back the value
+		if literal {
+			return interpolation.Expand(val, func(string) (string, bool) { return "", false })
+		}
+
+		return val, nil
+	case types.LayeredEnv:
+		return describeEnvConfig(val.Config, literal)
+	case map[string]interface{}:
+		_, encoded := val[setters.ENCODE_ENV_KEY]
+
+		setter := make(map[string]interface{}, len(val))
+		for key, item := range val {
+			if key == setters.SEPARATOR_ENV_KEY && encoded {
+				setter[key] = item
+			} else if key != setters.MODE_ENV_KEY && key != setters.SEPARATOR_ENV_KEY && !types.Contains(types.EnvMetadataFields, key) {
+				setter[key] = item
+			}
+		}
+
+		// A plain value only needs its mode
+		if value, exists := setter[setters.VALUE_ENV_KEY]; exists && len(setter) == 1 {
+			return describeEnvConfig(value, false)
+		}
+
+		out, err := json.Marshal(setter)
+		return string(out), err
+	case []interface{}:
+		out, err := json.Marshal(val)
+		return string(out), err
+	}
+
+	return fmt.Sprint(config), nil
+}
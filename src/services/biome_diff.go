@@ -0,0 +1,324 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/joho/godotenv"
+)
+
+const (
+	DIFF_KEYS   = "keys"   // Only compare which variables are set
+	DIFF_CONFIG = "config" // Compare the unresolved config of each variable
+	DIFF_VALUES = "values" // Compare the resolved values, secrets are hashed
+)
+
+var DiffModes = []string{DIFF_KEYS, DIFF_CONFIG, DIFF_VALUES}
+
+const (
+	DIFF_ADDED     = "added"     // Only on the right
+	DIFF_REMOVED   = "removed"   // Only on the left
+	DIFF_CHANGED   = "changed"   // On both sides with different values
+	DIFF_UNCHANGED = "unchanged" // On both sides with the same value
+)
+
+// DiffEntry compares a single named value of the two sides
+type DiffEntry struct {
+	Name   string  `json:"name"`
+	Change string  `json:"change"`
+	Left   *string `json:"left,omitempty"`  // Not set when the value is missing or only the keys are compared
+	Right  *string `json:"right,omitempty"` // Not set when the value is missing or only the keys are compared
+	Secret bool    `json:"secret,omitempty"`
+}
+
+// DiffLine is a line of a list (ex: commands) compared in order
+type DiffLine struct {
+	Change string `json:"change"`
+	Text   string `json:"text"`
+}
+
+// BiomeDiff is the difference between two biomes, or a biome and a dotenv file
+type BiomeDiff struct {
+	Left        string      `json:"left"`
+	Right       string      `json:"right"`
+	Mode        string      `json:"mode"`
+	AwsProfile  *DiffEntry  `json:"aws_profile,omitempty"` // Not compared against a dotenv file
+	Commands    []DiffLine  `json:"commands"`
+	Environment []DiffEntry `json:"environment"`
+}
+
+// Changed will determine if the two sides are different
+func (d BiomeDiff) Changed() bool {
+	if d.AwsProfile != nil && d.AwsProfile.Change != DIFF_UNCHANGED {
+		return true
+	}
+
+	for _, line := range d.Commands {
+		if line.Change != DIFF_UNCHANGED {
+			return true
+		}
+	}
+
+	for _, entry := range d.Environment {
+		if entry.Change != DIFF_UNCHANGED {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DiffBiomes will compare the merged config of two biomes
+//
+// Each biome is loaded and resolved on its own with the host environment put back in between,
+// so neither biome can see the variables set by the other. The params are given to the biomes that declare them
+func (svc *BiomeConfigurationService) DiffBiomes(left string, right string, mode string) (*BiomeDiff, error) {
+	if !types.Contains(DiffModes, mode) {
+		return nil, fmt.Errorf("unknown diff mode '%s', expected one of keys, config or values", mode)
+	}
+
+	leftDetails, err := svc.describeBiome(left, mode == DIFF_VALUES)
+	if err != nil {
+		return nil, err
+	}
+
+	rightDetails, err := svc.describeBiome(right, mode == DIFF_VALUES)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &BiomeDiff{
+		Left:        left,
+		Right:       right,
+		Mode:        mode,
+		AwsProfile:  diffSetting("aws_profile", leftDetails.AwsProfile, rightDetails.AwsProfile),
+		Commands:    diffLines(leftDetails.Commands, rightDetails.Commands),
+		Environment: diffEnvs(leftDetails.Environment, rightDetails.Environment, mode),
+	}
+
+	return diff, nil
+}
+
+// DiffBiomeWithDotenv will compare the variables of a biome with a dotenv file, such as one written by biome save
+//
+// Only the keys or the resolved values can be compared, the file has no config to compare
+func (svc *BiomeConfigurationService) DiffBiomeWithDotenv(name string, fpath string, mode string) (*BiomeDiff, error) {
+	if mode != DIFF_KEYS && mode != DIFF_VALUES {
+		return nil, fmt.Errorf("a biome can only be compared with a dotenv file by its keys or values")
+	}
+
+	loadedEnvs, err := godotenv.Read(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := svc.describeBiome(name, mode == DIFF_VALUES)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(loadedEnvs))
+	for key := range loadedEnvs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	fileEnvs := make([]EnvDetails, 0, len(keys))
+	for _, key := range keys {
+		fileEnvs = append(fileEnvs, EnvDetails{Name: key, Value: loadedEnvs[key], Resolved: true})
+	}
+
+	diff := &BiomeDiff{
+		Left:        name,
+		Right:       fpath,
+		Mode:        mode,
+		Commands:    []DiffLine{},
+		Environment: diffEnvs(details.Environment, fileEnvs, mode),
+	}
+
+	return diff, nil
+}
+
+// describeBiome will load and show a biome with a service of its own, putting the host environment back afterwards
+func (svc *BiomeConfigurationService) describeBiome(name string, resolve bool) (*BiomeDetails, error) {
+	biomeSvc := &BiomeConfigurationService{
+		configFileRepo: svc.configFileRepo,
+		awsStsRepo:     svc.awsStsRepo,
+		configuredEnvs: make(map[string]string),
+		configFiles:    svc.configFiles,
+		paramPrompt:    svc.paramPrompt,
+		cleanEnv:       svc.cleanEnv,
+		warnings:       svc.warnings,
+
+		secretsManagerRepo: svc.secretsManagerRepo,
+		parameterStoreRepo: svc.parameterStoreRepo,
+	}
+
+	if err := biomeSvc.LoadBiomeFromDefaults(name); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]string)
+	for param, val := range svc.paramArgs {
+		if _, declared := biomeSvc.ActiveBiome.Params[param]; declared {
+			params[param] = val
+		}
+	}
+
+	biomeSvc.SetParams(params)
+
+	defer saveHostEnv()()
+
+	details, err := biomeSvc.ShowBiome(ShowOptions{Resolve: resolve, Reveal: true})
+	if err != nil {
+		return nil, fmt.Errorf("biome '%s': %v", name, err)
+	}
+
+	return details, nil
+}
+
+// diffEnvs will compare the variables by name, the left variables come first in their order
+// followed by the variables only on the right
+func diffEnvs(left []EnvDetails, right []EnvDetails, mode string) []DiffEntry {
+	rightEnvs := make(map[string]EnvDetails, len(right))
+	for _, env := range right {
+		rightEnvs[env.Name] = env
+	}
+
+	leftEnvs := make(map[string]EnvDetails, len(left))
+	entries := []DiffEntry{}
+
+	for _, env := range left {
+		env := env
+		leftEnvs[env.Name] = env
+
+		var other *EnvDetails
+		if val, exists := rightEnvs[env.Name]; exists {
+			other = &val
+		}
+
+		entries = append(entries, diffEntry(env.Name, &env, other, mode))
+	}
+
+	for _, env := range right {
+		if _, exists := leftEnvs[env.Name]; !exists {
+			env := env
+			entries = append(entries, diffEntry(env.Name, nil, &env, mode))
+		}
+	}
+
+	return entries
+}
+
+// diffEntry will compare a variable that is on one or both sides, hashing the values of secrets
+// (including the variables that read a secret, see secretEnvs)
+func diffEntry(name string, left *EnvDetails, right *EnvDetails, mode string) DiffEntry {
+	entry := DiffEntry{
+		Name:   name,
+		Secret: (left != nil && left.Secret) || (right != nil && right.Secret),
+	}
+
+	value := func(env *EnvDetails) *string {
+		if env == nil || mode == DIFF_KEYS {
+			return nil
+		}
+
+		val := env.Value
+		if entry.Secret && env.Resolved {
+			val = hashSecret(val)
+		}
+
+		return &val
+	}
+
+	entry.Left, entry.Right = value(left), value(right)
+
+	switch {
+	case left == nil:
+		entry.Change = DIFF_ADDED
+	case right == nil:
+		entry.Change = DIFF_REMOVED
+	case mode != DIFF_KEYS && *entry.Left != *entry.Right:
+		entry.Change = DIFF_CHANGED
+	default:
+		entry.Change = DIFF_UNCHANGED
+	}
+
+	return entry
+}
+
+// diffSetting will compare a setting that both sides have, an empty setting is not set
+func diffSetting(name string, left string, right string) *DiffEntry {
+	entry := &DiffEntry{Name: name, Change: DIFF_UNCHANGED}
+
+	if left != "" {
+		entry.Left = &left
+	}
+
+	if right != "" {
+		entry.Right = &right
+	}
+
+	switch {
+	case left == right:
+	case left == "":
+		entry.Change = DIFF_ADDED
+	case right == "":
+		entry.Change = DIFF_REMOVED
+	default:
+		entry.Change = DIFF_CHANGED
+	}
+
+	return entry
+}
+
+// diffLines will compare two lists in order using their longest common subsequence
+func diffLines(left []string, right []string) []DiffLine {
+	// common[i][j] is the length of the longest common subsequence of left[i:] and right[j:]
+	common := make([][]int, len(left)+1)
+	for i := range common {
+		common[i] = make([]int, len(right)+1)
+	}
+
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	lines := []DiffLine{}
+	i, j := 0, 0
+
+	for i < len(left) || j < len(right) {
+		switch {
+		case i < len(left) && j < len(right) && left[i] == right[j]:
+			lines = append(lines, DiffLine{Change: DIFF_UNCHANGED, Text: left[i]})
+			i++
+			j++
+		case j == len(right) || (i < len(left) && common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, DiffLine{Change: DIFF_REMOVED, Text: left[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Change: DIFF_ADDED, Text: right[j]})
+			j++
+		}
+	}
+
+	return lines
+}
+
+// hashSecret will replace a secret with a short hash so equal secrets can be compared without showing them
+func hashSecret(val string) string {
+	sum := sha256.Sum256([]byte(val))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
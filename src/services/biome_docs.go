@@ -0,0 +1,154 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/jeff-roche/biome/src/lib/setters"
+	"github.com/jeff-roche/biome/src/lib/types"
+)
+
+// VariableDocs documents an environment variable of a biome
+type VariableDocs struct {
+	Name               string
+	Description        string
+	Example            string
+	Required           bool
+	Deprecated         bool
+	DeprecationMessage string
+	Secret             bool
+	Setter             string // The key that selects the setter (value, secret_arn, ...)
+	Mode               string
+	Config             string   // The unresolved config, as shown by biome show --no-resolve
+	Biome              string   // The biome that declares the variable
+	File               string   // The config file the variable is declared in
+	Line               int      // The line the variable is declared on
+	Local              bool     // Set by a local override file
+	Overrides          []string // The inherited biomes that also declare the variable, nearest first
+}
+
+// BiomeDocs documents a biome and every environment variable it sets
+type BiomeDocs struct {
+	Name        string
+	Description string
+	Tags        []string
+	File        string
+	Parents     []string
+	AwsProfile  string
+	LoadEnv     []string
+	Sources     []string
+	Variables   []VariableDocs
+}
+
+// DocumentBiome will document the loaded biome after inheritance without resolving anything
+//
+// Variables without a description or example use the ones from the nearest inherited biome that
+// declares the variable. Variables from load_env files and sources aren't documented, only the files
+// and sources are listed
+func (svc *BiomeConfigurationService) DocumentBiome() (*BiomeDocs, error) {
+	if svc.ActiveBiome == nil {
+		return nil, fmt.Errorf("no biome loaded")
+	}
+
+	biome := svc.ActiveBiome
+
+	// The biomes as they were written, to find the inherited biomes that declare each variable
+	var searchFiles []string
+	for _, searchPath := range svc.ConfigSearchPaths() {
+		searchFiles = append(searchFiles, searchPath.Path)
+	}
+
+	loaded, err := svc.configFileRepo.LoadBiomes(searchFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	genepool := make(map[string]*types.BiomeConfig, len(loaded))
+	for _, b := range loaded {
+		genepool[b.Name] = b
+	}
+
+	docs := &BiomeDocs{
+		Name:        biome.Name,
+		Description: biome.Description,
+		Tags:        append([]string{}, biome.Tags...),
+		File:        biome.SourceFile,
+		Parents:     append([]string{}, biome.Parents...),
+		AwsProfile:  biome.AwsProfile,
+		LoadEnv:     []string{},
+		Sources:     []string{},
+		Variables:   []VariableDocs{},
+	}
+
+	for _, file := range biome.EnvFiles {
+		docs.LoadEnv = append(docs.LoadEnv, file.ResolvedPath())
+	}
+
+	for _, source := range biome.Sources {
+		docs.Sources = append(docs.Sources, source.String())
+	}
+
+	secrets := svc.secretEnvs()
+
+	for _, env := range biome.EnvKeys() {
+		config := biome.Environment[env]
+		source := biome.EnvSource(env)
+		meta := types.GetEnvMetadata(config)
+
+		described, err := describeEnvConfig(config, false)
+		if err != nil {
+			return nil, fmt.Errorf("unable to describe '%s': %v", env, err)
+		}
+
+		vars := VariableDocs{
+			Name:               env,
+			Description:        meta.Description,
+			Example:            meta.Example,
+			Required:           meta.Required,
+			Deprecated:         meta.Deprecated,
+			DeprecationMessage: meta.DeprecationMessage,
+			Secret:             secrets[env],
+			Setter:             setters.SetterType(config),
+			Mode:               types.EnvMode(config),
+			Config:             described,
+			Biome:              source.Biome,
+			File:               source.File,
+			Line:               source.Line,
+			Local:              source.Local,
+			Overrides:          []string{},
+		}
+
+		// Every biome after the declaring biome in the lineage that also declares the variable is overridden
+		declared := false
+		for _, name := range append([]string{biome.Name}, biome.Parents...) {
+			if name == source.Biome {
+				declared = true
+				continue
+			}
+
+			inherited, exists := genepool[name]
+			if !declared || !exists {
+				continue
+			}
+
+			inheritedConfig, exists := inherited.Environment[env]
+			if !exists {
+				continue
+			}
+
+			vars.Overrides = append(vars.Overrides, name)
+
+			inheritedMeta := types.GetEnvMetadata(inheritedConfig)
+			if vars.Description == "" {
+				vars.Description = inheritedMeta.Description
+			}
+
+			if vars.Example == "" {
+				vars.Example = inheritedMeta.Example
+			}
+		}
+
+		docs.Variables = append(docs.Variables, vars)
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/jeff-roche/biome/src/repos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDocumentBiome(t *testing.T) {
+	getTestBiomes := func() []*types.BiomeConfig {
+		return []*types.BiomeConfig{
+			{
+				Name:       "base",
+				SourceFile: "base.yaml",
+				Environment: map[string]interface{}{
+					"DB_HOST": map[string]interface{}{"value": "localhost", "description": "The database host", "example": "db.internal"},
+					"OLD":     map[string]interface{}{"value": "1", "deprecated": "use NEW instead"},
+				},
+				EnvOrder: []string{"DB_HOST", "OLD"},
+			},
+			{
+				Name:        "svc",
+				SourceFile:  "svc.yaml",
+				Inheritance: types.StringList{"base"},
+				Environment: map[string]interface{}{
+					"DB_HOST": "127.0.0.1",
+					"TOKEN":   map[string]interface{}{"from_cli": true, "is_secret": true, "required": true},
+				},
+				EnvOrder: []string{"DB_HOST", "TOKEN"},
+			},
+		}
+	}
+
+	// Helper for building a service with the inherited svc biome loaded
+	getTestSvc := func() *BiomeConfigurationService {
+		biomes := getTestBiomes()
+		genepool := map[string]*types.BiomeConfig{"base": biomes[0], "svc": biomes[1]}
+
+		active := biomes[1].Copy()
+		if err := active.Inherit(genepool); err != nil {
+			t.Fatal(err)
+		}
+
+		mockRepo := new(repos.MockBiomeFileParser)
+		mockRepo.On("LoadBiomes", mock.Anything).Return(getTestBiomes(), nil)
+
+		return &BiomeConfigurationService{
+			ActiveBiome:    active,
+			configFileRepo: mockRepo,
+			configFiles:    []string{"svc.yaml"},
+		}
+	}
+
+	t.Run("should document every variable with the metadata of the biomes it overrides", func(t *testing.T) {
+		// Assemble
+		testSvc := getTestSvc()
+
+		// Act
+		docs, err := testSvc.DocumentBiome()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"base"}, docs.Parents)
+		assert.Len(t, docs.Variables, 3)
+		assert.Equal(t, VariableDocs{
+			Name:        "DB_HOST",
+			Description: "The database host",
+			Example:     "db.internal",
+			Setter:      "value",
+			Config:      "127.0.0.1",
+			Biome:       "svc",
+			File:        "svc.yaml",
+			Overrides:   []string{"base"},
+		}, docs.Variables[0])
+	})
+
+	t.Run("should document secrets, required and deprecated variables", func(t *testing.T) {
+		// Assemble
+		testSvc := getTestSvc()
+
+		// Act
+		docs, err := testSvc.DocumentBiome()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "TOKEN", docs.Variables[1].Name)
+		assert.True(t, docs.Variables[1].Secret)
+		assert.True(t, docs.Variables[1].Required)
+		assert.Equal(t, `{"from_cli":true,"is_secret":true}`, docs.Variables[1].Config)
+		assert.Equal(t, "OLD", docs.Variables[2].Name)
+		assert.True(t, docs.Variables[2].Deprecated)
+		assert.Equal(t, "use NEW instead", docs.Variables[2].DeprecationMessage)
+		assert.Equal(t, "base", docs.Variables[2].Biome)
+		assert.Empty(t, docs.Variables[2].Overrides)
+	})
+
+	t.Run("should report an error if the biome is not loaded", func(t *testing.T) {
+		// Assemble
+		testSvc := &BiomeConfigurationService{}
+
+		// Act
+		_, err := testSvc.DocumentBiome()
+
+		// Assert
+		assert.NotNil(t, err)
+	})
+}
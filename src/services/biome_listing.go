@@ -0,0 +1,79 @@
+package services
+
+import (
+	"github.com/jeff-roche/biome/src/lib/types"
+)
+
+// BiomeSummary describes a biome for listing
+type BiomeSummary struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags"`
+	File        string   `json:"file"`
+	Line        int      `json:"line,omitempty"`
+	Parents     []string `json:"parents"`               // Every inherited biome, in precedence order
+	AwsProfile  string   `json:"aws_profile,omitempty"` // The AWS profile after inheritance
+	Error       string   `json:"error,omitempty"`       // Why the inheritance couldn't be applied
+}
+
+// HasTags will determine if the biome has every one of the tags
+func (s BiomeSummary) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !types.Contains(s.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ListBiomes will summarize every biome in the config files, in the order they were found,
+// that has all of the tags given
+//
+// Problems with a biome's inheritance are reported on the biome rather than failing the listing
+func (svc *BiomeConfigurationService) ListBiomes(tags []string) ([]BiomeSummary, error) {
+	var searchFiles []string
+	for _, searchPath := range svc.ConfigSearchPaths() {
+		searchFiles = append(searchFiles, searchPath.Path)
+	}
+
+	biomes, err := svc.configFileRepo.LoadBiomes(searchFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	genepool := make(map[string]*types.BiomeConfig, len(biomes))
+	for _, biome := range biomes {
+		genepool[biome.Name] = biome
+	}
+
+	summaries := []BiomeSummary{}
+	for _, biome := range biomes {
+		summary := BiomeSummary{
+			Name:        biome.Name,
+			Description: biome.Description,
+			Tags:        append([]string{}, biome.Tags...),
+			File:        biome.SourceFile,
+			Line:        biome.SourceLine,
+			Parents:     []string{},
+			AwsProfile:  biome.AwsProfile,
+		}
+
+		if !summary.HasTags(tags) {
+			continue
+		}
+
+		// Inherit on a copy so the genepool stays as it was written
+		merged := biome.Copy()
+		if err := merged.Inherit(genepool); err != nil {
+			summary.Error = err.Error()
+		} else {
+			summary.Parents = append(summary.Parents, merged.Parents...)
+			summary.AwsProfile = merged.AwsProfile
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/jeff-roche/biome/src/repos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListBiomes(t *testing.T) {
+	// Helper for building a service with a mocked parser
+	getTestSvc := func(biomes []*types.BiomeConfig, err error) *BiomeConfigurationService {
+		mockRepo := new(repos.MockBiomeFileParser)
+		mockRepo.On("LoadBiomes", mock.Anything).Return(biomes, err)
+
+		return &BiomeConfigurationService{
+			configFileRepo: mockRepo,
+			configFiles:    []string{"test.yaml"},
+		}
+	}
+
+	getTestBiomes := func() []*types.BiomeConfig {
+		return []*types.BiomeConfig{
+			{Name: "base", AwsProfile: "shared", Tags: types.StringList{"aws"}, SourceFile: "test.yaml", SourceLine: 1},
+			{Name: "k8s", Tags: types.StringList{"k8s"}},
+			{Name: "svc", Description: "Deploys", Inheritance: types.StringList{"base", "k8s"}, Tags: types.StringList{"aws", "deploy"}},
+			{Name: "orphan", Inheritance: types.StringList{"ghost"}},
+		}
+	}
+
+	t.Run("should summarize every biome in order with its inheritance applied", func(t *testing.T) {
+		// Assemble
+		biomes := getTestBiomes()
+		testSvc := getTestSvc(biomes, nil)
+
+		// Act
+		summaries, err := testSvc.ListBiomes(nil)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Len(t, summaries, 4)
+		assert.Equal(t, BiomeSummary{
+			Name: "base", Tags: []string{"aws"}, File: "test.yaml", Line: 1, Parents: []string{}, AwsProfile: "shared",
+		}, summaries[0])
+		assert.Equal(t, []string{"base", "k8s"}, summaries[2].Parents)
+		assert.Equal(t, "shared", summaries[2].AwsProfile)
+		assert.Equal(t, "", biomes[2].AwsProfile)
+		assert.Contains(t, summaries[3].Error, "ghost")
+	})
+
+	t.Run("should only list the biomes with every tag", func(t *testing.T) {
+		// Assemble
+		testSvc := getTestSvc(getTestBiomes(), nil)
+
+		// Act
+		summaries, err := testSvc.ListBiomes([]string{"aws", "deploy"})
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Len(t, summaries, 1)
+		assert.Equal(t, "svc", summaries[0].Name)
+	})
+
+	t.Run("should report errors loading the config", func(t *testing.T) {
+		// Assemble
+		testErr := fmt.Errorf("bad config")
+		testSvc := getTestSvc(nil, testErr)
+
+		// Act
+		_, err := testSvc.ListBiomes(nil)
+
+		// Assert
+		assert.ErrorIs(t, err, testErr)
+	})
+}
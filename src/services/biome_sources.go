@@ -0,0 +1,192 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jeff-roche/biome/src/lib/envfile"
+	"github.com/jeff-roche/biome/src/lib/fileio"
+	"github.com/jeff-roche/biome/src/lib/interpolation"
+	"github.com/jeff-roche/biome/src/lib/setters"
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/jeff-roche/biome/src/repos"
+)
+
+// SOURCES_SETTER is the setter reported for variables expanded from the biome's sources
+const SOURCES_SETTER = "sources"
+
+// sourceVariable is a key read from a source, before it is filtered and named
+type sourceVariable struct {
+	Key   string
+	Value string
+	File  string // Where the key was read from, the config file for sources in AWS
+	Line  int
+}
+
+// loadSources will expand the biome's sources into environment variables
+//
+// Later sources take precedence over the sources before them, so the biome's own sources win over the
+// inherited ones, and the environment block and load_env files take precedence over every source.
+// Without resolve only file sources are read so nothing is fetched from AWS
+func (svc *BiomeConfigurationService) loadSources(resolve bool) error {
+	values := make(map[string]string)
+	loadedEnvs := make(map[string]types.EnvSource)
+	var order []string
+
+	for _, source := range svc.ActiveBiome.Sources {
+		if !resolve && source.Kind() != types.SOURCE_FILE {
+			continue
+		}
+
+		vars, err := svc.readSource(source)
+		if err != nil {
+			return fmt.Errorf("unable to load the source '%s': %v", source, err)
+		}
+
+		for _, v := range vars {
+			name, ok := source.VariableName(v.Key)
+			if !ok {
+				continue
+			}
+
+			if _, exists := loadedEnvs[name]; !exists {
+				order = append(order, name)
+			}
+
+			values[name] = v.Value
+			loadedEnvs[name] = types.EnvSource{
+				Biome:  source.Biome,
+				File:   v.File,
+				Line:   v.Line,
+				From:   source.String(),
+				Secret: source.Kind() == types.SOURCE_SECRET_ARN || source.SsmDecrypt,
+			}
+		}
+	}
+
+	// Source values are taken literally so they are escaped from interpolation
+	for _, name := range order {
+		if _, exists := svc.ActiveBiome.Environment[name]; !exists {
+			svc.ActiveBiome.AddEnvFrom(name, interpolation.Escape(values[name]), loadedEnvs[name])
+		}
+	}
+
+	return nil
+}
+
+// readSource will read every key of the source
+func (svc *BiomeConfigurationService) readSource(source types.SourceConfig) ([]sourceVariable, error) {
+	switch source.Kind() {
+	case types.SOURCE_SECRET_ARN:
+		return svc.readSecretSource(source)
+	case types.SOURCE_SSM_PATH:
+		return svc.readSsmSource(source)
+	}
+
+	file := source.EnvFile()
+	fpath := file.ResolvedPath()
+
+	if !fileio.FileExists(fpath) {
+		if file.Optional {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("the file '%s' does not exist", fpath)
+	}
+
+	envs, err := envfile.Read(fpath, file.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make([]sourceVariable, 0, len(envs))
+	for _, env := range envs {
+		vars = append(vars, sourceVariable{Key: env.Name, Value: env.Value, File: fpath, Line: env.Line})
+	}
+
+	return vars, nil
+}
+
+// readSecretSource will read every key of a JSON secret, values that aren't strings are kept as JSON
+func (svc *BiomeConfigurationService) readSecretSource(source types.SourceConfig) ([]sourceVariable, error) {
+	repo, err := svc.secretsManagerRepo()
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize the secrets manager repository: %v", err)
+	}
+
+	secret, err := repo.GetSecretString(source.SecretArn)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+
+	decoder := json.NewDecoder(strings.NewReader(secret))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&data); err != nil || data == nil {
+		return nil, fmt.Errorf("the secret needs to be a JSON object")
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	vars := make([]sourceVariable, 0, len(keys))
+	for _, key := range keys {
+		val, err := setters.SecretValueString(data[key])
+		if err != nil {
+			return nil, err
+		}
+
+		vars = append(vars, sourceVariable{Key: key, Value: val, File: source.ConfigFile, Line: source.Line})
+	}
+
+	return vars, nil
+}
+
+// readSsmSource will read every parameter under the path, keyed by its path below it with / replaced by _
+func (svc *BiomeConfigurationService) readSsmSource(source types.SourceConfig) ([]sourceVariable, error) {
+	repo, err := svc.parameterStoreRepo()
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize the parameter store repository: %v", err)
+	}
+
+	params, err := repo.GetParametersByPath(source.SsmPath, source.SsmDecrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(source.SsmPath, "/") + "/"
+
+	vars := make([]sourceVariable, 0, len(params))
+	for _, param := range params {
+		if param.Type == setters.SSM_SECURE_STRING && !source.SsmDecrypt {
+			return nil, fmt.Errorf("the parameter '%s' is a %s, set 'ssm_decrypt: true' to decrypt it", param.Name, setters.SSM_SECURE_STRING)
+		}
+
+		key := strings.ReplaceAll(strings.TrimPrefix(param.Name, prefix), "/", "_")
+		vars = append(vars, sourceVariable{Key: key, Value: param.Value, File: source.ConfigFile, Line: source.Line})
+	}
+
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Key < vars[j].Key
+	})
+
+	return vars, nil
+}
+
+// newSecretsManagerRepo builds the Secrets Manager repository once the AWS session of the biome is configured
+func newSecretsManagerRepo() (repos.SecretsManagerIfc, error) {
+	return repos.NewSecretsManagerRepo()
+}
+
+// newParameterStoreRepo builds the Parameter Store repository once the AWS session of the biome is configured
+func newParameterStoreRepo() (repos.ParameterStoreIfc, error) {
+	return repos.NewParameterStoreRepo()
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/jeff-roche/biome/src/repos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBiomeSources(t *testing.T) {
+	envs := []string{"BIOME_TEST_DB_HOST", "BIOME_TEST_DB_PORT", "BIOME_TEST_API_KEY", "BIOME_TEST_DEBUG", "BIOME_TEST_PASSWORD", "BIOME_TEST_FEATURE"}
+
+	// Helper for a service with mock repos, the biome's config file is in dir
+	getTestService := func(t *testing.T, dir string, sources ...types.SourceConfig) (*BiomeConfigurationService, *repos.MockSecretsManager, *repos.MockParameterStore) {
+		configFile := filepath.Join(dir, ".biome.yaml")
+		for i := range sources {
+			sources[i].Biome = "app"
+			sources[i].ConfigFile = configFile
+			sources[i].Line = i + 3
+		}
+
+		secretsRepo := &repos.MockSecretsManager{}
+		ssmRepo := &repos.MockParameterStore{}
+
+		testSvc := &BiomeConfigurationService{
+			ActiveBiome:    &types.BiomeConfig{Name: "app", SourceFile: configFile, Sources: sources},
+			configuredEnvs: map[string]string{},
+			secretsManagerRepo: func() (repos.SecretsManagerIfc, error) {
+				return secretsRepo, nil
+			},
+			parameterStoreRepo: func() (repos.ParameterStoreIfc, error) {
+				return ssmRepo, nil
+			},
+		}
+
+		t.Cleanup(func() {
+			for _, env := range envs {
+				os.Unsetenv(env)
+			}
+		})
+
+		return testSvc, secretsRepo, ssmRepo
+	}
+
+	t.Run("should expand every source with its filters and names", func(t *testing.T) {
+		// Assemble
+		dir := t.TempDir()
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.env"), []byte("debug=true\nignored=x\n"), 0644))
+
+		testSvc, secretsRepo, ssmRepo := getTestService(t, dir,
+			types.SourceConfig{SecretArn: "prod/app", Prefix: "BIOME_TEST_", Case: types.SOURCE_CASE_UPPER, Rename: map[string]string{"pw": "BIOME_TEST_PASSWORD"}},
+			types.SourceConfig{SsmPath: "/app/prod/", Prefix: "BIOME_TEST_", Case: types.SOURCE_CASE_UPPER, Exclude: types.StringList{"internal_*"}},
+			types.SourceConfig{File: "app.env", Prefix: "BIOME_TEST_", Case: types.SOURCE_CASE_UPPER, Include: types.StringList{"debug"}},
+		)
+
+		secretsRepo.On("GetSecretString", "prod/app").Return(`{"pw": "hunter2", "db_port": 5432}`, nil)
+		ssmRepo.On("GetParametersByPath", "/app/prod/", false).Return([]repos.SsmParameter{
+			{Name: "/app/prod/db/host", Value: "db.internal", Type: "String"},
+			{Name: "/app/prod/internal/token", Value: "x", Type: "String"},
+		}, nil)
+
+		// Act
+		err := testSvc.ActivateBiome()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "hunter2", os.Getenv("BIOME_TEST_PASSWORD"))
+		assert.Equal(t, "5432", os.Getenv("BIOME_TEST_DB_PORT"))
+		assert.Equal(t, "db.internal", os.Getenv("BIOME_TEST_DB_HOST"))
+		assert.Equal(t, "true", os.Getenv("BIOME_TEST_DEBUG"))
+		assert.NotContains(t, testSvc.configuredEnvs, "BIOME_TEST_INTERNAL_TOKEN")
+		assert.NotContains(t, testSvc.configuredEnvs, "BIOME_TEST_IGNORED")
+		assert.Equal(t, types.EnvSource{Biome: "app", File: filepath.Join(dir, ".biome.yaml"), Line: 4, From: "ssm_path /app/prod/"}, testSvc.ActiveBiome.EnvSource("BIOME_TEST_DB_HOST"))
+		assert.Equal(t, types.EnvSource{Biome: "app", File: filepath.Join(dir, "app.env"), Line: 1, From: "file " + filepath.Join(dir, "app.env")}, testSvc.ActiveBiome.EnvSource("BIOME_TEST_DEBUG"))
+	})
+
+	t.Run("should give the environment block and load_env files precedence over the sources", func(t *testing.T) {
+		// Assemble
+		dir := t.TempDir()
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("BIOME_TEST_DB_PORT=6543\n"), 0644))
+
+		testSvc, secretsRepo, _ := getTestService(t, dir,
+			types.SourceConfig{SecretArn: "shared"},
+			types.SourceConfig{SecretArn: "app"},
+		)
+
+		testSvc.ActiveBiome.EnvFiles = types.EnvFileList{{Path: ".env", Dir: dir}}
+		testSvc.ActiveBiome.AddEnv("BIOME_TEST_DB_HOST", "localhost")
+
+		secretsRepo.On("GetSecretString", "shared").Return(`{"BIOME_TEST_DB_HOST": "shared", "BIOME_TEST_DB_PORT": "1", "BIOME_TEST_API_KEY": "shared", "BIOME_TEST_FEATURE": "${HOME}"}`, nil)
+		secretsRepo.On("GetSecretString", "app").Return(`{"BIOME_TEST_API_KEY": "app"}`, nil)
+
+		// Act
+		err := testSvc.ActivateBiome()
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, "localhost", os.Getenv("BIOME_TEST_DB_HOST"))
+		assert.Equal(t, "6543", os.Getenv("BIOME_TEST_DB_PORT"))
+		assert.Equal(t, "app", os.Getenv("BIOME_TEST_API_KEY"))
+		assert.Equal(t, "${HOME}", os.Getenv("BIOME_TEST_FEATURE"))
+	})
+
+	t.Run("should show the sources without contacting AWS unless resolved", func(t *testing.T) {
+		// Assemble
+		dir := t.TempDir()
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.env"), []byte("BIOME_TEST_DEBUG=true\n"), 0644))
+
+		testSvc, secretsRepo, _ := getTestService(t, dir,
+			types.SourceConfig{SecretArn: "prod/app"},
+			types.SourceConfig{File: "app.env"},
+		)
+
+		secretsRepo.On("GetSecretString", "prod/app").Return(`{"BIOME_TEST_PASSWORD": "hunter2"}`, nil)
+
+		// Act
+		unresolved, unresolvedErr := testSvc.ShowBiome(ShowOptions{})
+		unresolvedCalls := len(secretsRepo.Calls)
+		resolved, resolvedErr := testSvc.ShowBiome(ShowOptions{Resolve: true})
+
+		// Assert
+		assert.Nil(t, unresolvedErr)
+		assert.Nil(t, resolvedErr)
+		assert.Equal(t, 0, unresolvedCalls)
+		assert.Equal(t, []string{"secret_arn prod/app", "file " + filepath.Join(dir, "app.env")}, unresolved.Sources)
+		assert.Len(t, unresolved.Environment, 1)
+
+		assert.Equal(t, EnvDetails{
+			Name:     "BIOME_TEST_PASSWORD",
+			Value:    REDACTED,
+			Resolved: true,
+			Secret:   true,
+			Setter:   SOURCES_SETTER,
+			Biome:    "app",
+			File:     filepath.Join(dir, ".biome.yaml"),
+			Line:     3,
+			From:     "secret_arn prod/app",
+		}, resolved.Environment[1])
+	})
+
+	t.Run("should report sources that can't be loaded", func(t *testing.T) {
+		// Assemble
+		testSvc, secretsRepo, ssmRepo := getTestService(t, t.TempDir(),
+			types.SourceConfig{SecretArn: "not-json"},
+		)
+		ssmSvc, _, _ := getTestService(t, t.TempDir(),
+			types.SourceConfig{SsmPath: "/app/prod"},
+		)
+		ssmSvc.parameterStoreRepo = testSvc.parameterStoreRepo
+
+		secretsRepo.On("GetSecretString", "not-json").Return("hunter2", nil)
+		ssmRepo.On("GetParametersByPath", "/app/prod", false).Return([]repos.SsmParameter{
+			{Name: "/app/prod/password", Value: "AQICAH...", Type: "SecureString"},
+		}, nil)
+
+		// Act
+		secretErr := testSvc.ActivateBiome()
+		ssmErr := ssmSvc.ActivateBiome()
+
+		// Assert
+		assert.EqualError(t, secretErr, "unable to load the source 'secret_arn not-json': the secret needs to be a JSON object")
+		assert.EqualError(t, ssmErr, "unable to load the source 'ssm_path /app/prod': the parameter '/app/prod/password' is a SecureString, set 'ssm_decrypt: true' to decrypt it")
+	})
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jeff-roche/biome/src/lib/envfile"
+	"github.com/jeff-roche/biome/src/lib/fileio"
+	"github.com/jeff-roche/biome/src/lib/interpolation"
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/jeff-roche/biome/src/repos"
+)
+
+// ValidationProblem is a problem found while validating the config files
+type ValidationProblem struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Biome   string `json:"biome,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationReport is the result of validating every biome in the config files
+type ValidationReport struct {
+	Files    []string            `json:"files"`
+	Biomes   []string            `json:"biomes"`
+	Problems []ValidationProblem `json:"problems"`
+}
+
+// Valid will report whether any problems were found
+func (r ValidationReport) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+// ValidateConfig will check every biome in the config files without resolving any values
+//
+// This covers everything the config parser reports (strict parsing should be enabled to catch
+// unknown keys and malformed setters), the inheritance of every biome and any env files they load
+func (svc *BiomeConfigurationService) ValidateConfig() ValidationReport {
+	report := ValidationReport{
+		Problems: []ValidationProblem{},
+	}
+
+	var searchFiles []string
+	for _, searchPath := range svc.ConfigSearchPaths() {
+		searchFiles = append(searchFiles, searchPath.Path)
+
+		if fileio.FileExists(searchPath.Path) {
+			report.Files = append(report.Files, searchPath.Path)
+		}
+	}
+
+	biomes, err := svc.configFileRepo.LoadBiomes(searchFiles)
+	if err != nil {
+		var cfgErrs repos.ConfigErrors
+		if !errors.As(err, &cfgErrs) {
+			report.Problems = append(report.Problems, ValidationProblem{Message: err.Error()})
+			return report
+		}
+
+		for _, cfgErr := range cfgErrs {
+			report.Problems = append(report.Problems, ValidationProblem{
+				File:    cfgErr.File,
+				Line:    cfgErr.Line,
+				Column:  cfgErr.Column,
+				Message: cfgErr.Message,
+			})
+		}
+
+		return report
+	}
+
+	genepool := make(map[string]*types.BiomeConfig, len(biomes))
+	for _, biome := range biomes {
+		genepool[biome.Name] = biome
+	}
+
+	for _, biome := range biomes {
+		report.Biomes = append(report.Biomes, biome.Name)
+		report.Problems = append(report.Problems, validateBiome(biome, genepool)...)
+	}
+
+	return report
+}
+
+// validateBiome will check the biome once its inheritance has been applied
+func validateBiome(biome *types.BiomeConfig, genepool map[string]*types.BiomeConfig) []ValidationProblem {
+	newProblem := func(format string, args ...interface{}) ValidationProblem {
+		return ValidationProblem{
+			File:    biome.SourceFile,
+			Line:    biome.SourceLine,
+			Biome:   biome.Name,
+			Message: fmt.Sprintf(format, args...),
+		}
+	}
+
+	// Inherit on a copy so the genepool stays as it was written
+	merged := biome.Copy()
+	if err := merged.Inherit(genepool); err != nil {
+		return []ValidationProblem{newProblem("biome '%s': %v", biome.Name, err)}
+	}
+
+	var problems []ValidationProblem
+
+	for _, name := range merged.ParamKeys() {
+		param := merged.Params[name]
+		if !param.Required() && len(param.Allowed) > 0 && !types.Contains(param.Allowed, *param.Default) {
+			problems = append(problems, newProblem("the default '%s' of param '%s' is not one of its allowed values", *param.Default, name))
+		}
+	}
+
+	// Env files built from params or variables can only be checked when the biome is activated
+	for _, file := range merged.EnvFiles {
+		if len(interpolation.References(file.Path)) > 0 {
+			continue
+		}
+
+		fpath := file.ResolvedPath()
+		if !fileio.FileExists(fpath) {
+			if !file.Optional {
+				problems = append(problems, newProblem("biome '%s' loads env file '%s' which does not exist", biome.Name, fpath))
+			}
+
+			continue
+		}
+
+		if _, err := envfile.Read(fpath, file.Format); err != nil {
+			problems = append(problems, newProblem("biome '%s' loads an env file that can't be read: %v", biome.Name, err))
+		}
+	}
+
+	// Sources in AWS can only be checked when the biome is activated
+	for _, source := range merged.Sources {
+		if source.Kind() != types.SOURCE_FILE || len(interpolation.References(source.File)) > 0 {
+			continue
+		}
+
+		file := source.EnvFile()
+		fpath := file.ResolvedPath()
+		if !fileio.FileExists(fpath) {
+			if !file.Optional {
+				problems = append(problems, newProblem("biome '%s' has a source file '%s' which does not exist", biome.Name, fpath))
+			}
+
+			continue
+		}
+
+		if _, err := envfile.Read(fpath, file.Format); err != nil {
+			problems = append(problems, newProblem("biome '%s' has a source file that can't be read: %v", biome.Name, err))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/jeff-roche/biome/src/repos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValidateConfig(t *testing.T) {
+	// Helper for building a service with a mocked parser
+	getTestSvc := func(biomes []*types.BiomeConfig, err error) *BiomeConfigurationService {
+		mockRepo := new(repos.MockBiomeFileParser)
+		mockRepo.On("LoadBiomes", mock.Anything).Return(biomes, err)
+
+		return &BiomeConfigurationService{
+			configFileRepo: mockRepo,
+			configFiles:    []string{"test.yaml"},
+		}
+	}
+
+	t.Run("should report a valid config", func(t *testing.T) {
+		// Assemble
+		envFile := filepath.Join(t.TempDir(), "test.env")
+		assert.Nil(t, os.WriteFile(envFile, []byte("A=a\n"), 0644))
+
+		testSvc := getTestSvc([]*types.BiomeConfig{
+			{Name: "parent", EnvFiles: types.EnvFileList{{Path: envFile}, {Path: "missing.env", Optional: true}}},
+			{Name: "child", Inheritance: types.StringList{"parent"}},
+		}, nil)
+
+		// Act
+		report := testSvc.ValidateConfig()
+
+		// Assert
+		assert.True(t, report.Valid())
+		assert.Equal(t, []string{"parent", "child"}, report.Biomes)
+	})
+
+	t.Run("should report inheritance problems without changing the biomes", func(t *testing.T) {
+		// Assemble
+		parent := &types.BiomeConfig{Name: "parent", Commands: []string{"parent"}}
+		child := &types.BiomeConfig{Name: "child", Inheritance: types.StringList{"parent"}, Commands: []string{"child"}}
+		orphan := &types.BiomeConfig{Name: "orphan", Inheritance: types.StringList{"ghost"}, SourceFile: "test.yaml", SourceLine: 7}
+		testSvc := getTestSvc([]*types.BiomeConfig{parent, child, orphan}, nil)
+
+		// Act
+		report := testSvc.ValidateConfig()
+
+		// Assert
+		assert.False(t, report.Valid())
+		assert.Len(t, report.Problems, 1)
+		assert.Equal(t, "orphan", report.Problems[0].Biome)
+		assert.Equal(t, 7, report.Problems[0].Line)
+		assert.Contains(t, report.Problems[0].Message, "ghost")
+		assert.Equal(t, []string{"child"}, child.Commands)
+	})
+
+	t.Run("should report missing env files", func(t *testing.T) {
+		// Assemble
+		testSvc := getTestSvc([]*types.BiomeConfig{
+			{Name: "parent", EnvFiles: types.EnvFileList{{Path: "does-not-exist.env"}}},
+			{Name: "child", Inheritance: types.StringList{"parent"}},
+		}, nil)
+
+		// Act
+		report := testSvc.ValidateConfig()
+
+		// Assert
+		assert.Len(t, report.Problems, 2)
+		assert.Contains(t, report.Problems[1].Message, "does-not-exist.env")
+	})
+
+	t.Run("should report env files that can't be read", func(t *testing.T) {
+		// Assemble
+		envFile := filepath.Join(t.TempDir(), "test.json")
+		assert.Nil(t, os.WriteFile(envFile, []byte("{\n  \"A\": \"a\",\n  \"B\": [1]\n}"), 0644))
+
+		testSvc := getTestSvc([]*types.BiomeConfig{
+			{Name: "parent", EnvFiles: types.EnvFileList{{Path: envFile}}},
+		}, nil)
+
+		// Act
+		report := testSvc.ValidateConfig()
+
+		// Assert
+		assert.Len(t, report.Problems, 1)
+		assert.Contains(t, report.Problems[0].Message, envFile+":3:")
+	})
+
+	t.Run("should check param defaults and skip env files built from params", func(t *testing.T) {
+		// Assemble
+		badDefault := "eu-west-1"
+		testSvc := getTestSvc([]*types.BiomeConfig{
+			{
+				Name:     "svc-deploy",
+				EnvFiles: types.EnvFileList{{Path: "${service}.env"}},
+				Params: map[string]types.ParamConfig{
+					"service": {},
+					"region":  {Default: &badDefault, Allowed: []string{"us-east-1", "us-west-2"}},
+				},
+			},
+		}, nil)
+
+		// Act
+		report := testSvc.ValidateConfig()
+
+		// Assert
+		assert.Len(t, report.Problems, 1)
+		assert.Equal(t, "the default 'eu-west-1' of param 'region' is not one of its allowed values", report.Problems[0].Message)
+	})
+
+	t.Run("should report the config errors with their location", func(t *testing.T) {
+		// Assemble
+		testSvc := getTestSvc(nil, repos.ConfigErrors{
+			{File: "test.yaml", Line: 2, Column: 1, Message: "unknown field 'enviroment'"},
+		})
+
+		// Act
+		report := testSvc.ValidateConfig()
+
+		// Assert
+		assert.Equal(t, []ValidationProblem{
+			{File: "test.yaml", Line: 2, Column: 1, Message: "unknown field 'enviroment'"},
+		}, report.Problems)
+	})
+
+	t.Run("should report other errors loading the config", func(t *testing.T) {
+		// Assemble
+		testSvc := getTestSvc(nil, fmt.Errorf("circular include found"))
+
+		// Act
+		report := testSvc.ValidateConfig()
+
+		// Assert
+		assert.Equal(t, "circular include found", report.Problems[0].Message)
+	})
+}
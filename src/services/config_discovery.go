@@ -0,0 +1,167 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jeff-roche/biome/src/lib/fileio"
+	"github.com/jeff-roche/biome/src/repos"
+)
+
+const (
+	CONFIG_ENV_VAR         = "BIOME_CONFIG"      // A list of config files to use instead of discovery
+	CONFIG_CEILING_ENV_VAR = "BIOME_CEILING_DIR" // The directory to stop walking up at
+	systemConfigDir        = "/etc/biome.d"
+)
+
+var defaultFileNames = []string{".biome.yaml", ".biome.yml"}
+var configExtensions = []string{".yaml", ".yml"}
+
+// ConfigSearchPath is a config file that will be searched for biomes and why it is searched
+type ConfigSearchPath struct {
+	Path   string
+	Source string
+}
+
+// configDiscovery holds everything config discovery depends on so it can be tested
+type configDiscovery struct {
+	explicit []string // Files from the --config flag
+	cwd      string
+	home     string
+	system   string // The system wide config directory
+	getenv   func(string) string
+}
+
+// ConfigSearchPaths will return every config file that will be searched for biomes, in precedence order
+//
+// Files passed with --config are used on their own, otherwise the files in the BIOME_CONFIG env var are.
+// Without either the files are discovered in this order:
+//   - .biome.[yaml|yml] in the current directory and each parent directory, stopping at the
+//     repository root (a directory containing .git), BIOME_CEILING_DIR or the filesystem root
+//   - .biome.[yaml|yml] in the current user's home directory
+//   - Every yaml file in $XDG_CONFIG_HOME/biome/ (~/.config/biome/ by default)
+//   - Every yaml file in /etc/biome.d/
+//
+// A local override file (.biome.local.yaml) next to any of the files is merged on top of it
+func (svc BiomeConfigurationService) ConfigSearchPaths() []ConfigSearchPath {
+	discovery := configDiscovery{
+		explicit: svc.configFiles,
+		system:   systemConfigDir,
+		getenv:   os.Getenv,
+	}
+
+	if dir, err := fileio.GetCD(); err == nil {
+		discovery.cwd = dir
+	}
+
+	if dir, err := fileio.GetHomeDir(); err == nil {
+		discovery.home = dir
+	}
+
+	return discovery.searchPaths()
+}
+
+func (d configDiscovery) searchPaths() []ConfigSearchPath {
+	if len(d.explicit) > 0 {
+		return toSearchPaths(d.explicit, "--config")
+	}
+
+	if envFiles := d.getenv(CONFIG_ENV_VAR); envFiles != "" {
+		return toSearchPaths(filepath.SplitList(envFiles), CONFIG_ENV_VAR)
+	}
+
+	var paths []ConfigSearchPath
+	seen := make(map[string]bool)
+	add := func(fpath string, source string) {
+		if !seen[fpath] {
+			seen[fpath] = true
+			paths = append(paths, ConfigSearchPath{Path: fpath, Source: source})
+		}
+	}
+
+	// Current directory up to the boundary
+	if d.cwd != "" {
+		ceiling := d.getenv(CONFIG_CEILING_ENV_VAR)
+		if ceiling != "" {
+			ceiling = filepath.Clean(ceiling)
+		}
+
+		for dir := filepath.Clean(d.cwd); ; dir = filepath.Dir(dir) {
+			for _, fname := range defaultFileNames {
+				add(filepath.Join(dir, fname), "directory")
+			}
+
+			if dir == ceiling || fileio.FileExists(filepath.Join(dir, ".git")) || filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+
+	// Home directory
+	if d.home != "" {
+		for _, fname := range defaultFileNames {
+			add(filepath.Join(d.home, fname), "home")
+		}
+	}
+
+	// XDG config directory
+	xdgDir := d.getenv("XDG_CONFIG_HOME")
+	if xdgDir == "" && d.home != "" {
+		xdgDir = filepath.Join(d.home, ".config")
+	}
+
+	if xdgDir != "" {
+		for _, fpath := range getConfigFilesInDir(filepath.Join(xdgDir, "biome")) {
+			add(fpath, "xdg")
+		}
+	}
+
+	// System wide
+	if d.system != "" {
+		for _, fpath := range getConfigFilesInDir(d.system) {
+			add(fpath, "system")
+		}
+	}
+
+	return paths
+}
+
+// getConfigFilesInDir will return every yaml file in the directory in alphabetical order
+func getConfigFilesInDir(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, entry := range entries {
+		// Local override files are loaded with the config file they sit next to
+		if entry.IsDir() || repos.IsLocalConfig(entry.Name()) {
+			continue
+		}
+
+		for _, ext := range configExtensions {
+			if strings.HasSuffix(entry.Name(), ext) {
+				files = append(files, filepath.Join(dir, entry.Name()))
+				break
+			}
+		}
+	}
+
+	sort.Strings(files)
+
+	return files
+}
+
+func toSearchPaths(files []string, source string) []ConfigSearchPath {
+	paths := make([]ConfigSearchPath, 0, len(files))
+	for _, fpath := range files {
+		if fpath != "" {
+			paths = append(paths, ConfigSearchPath{Path: fpath, Source: source})
+		}
+	}
+
+	return paths
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigDiscovery(t *testing.T) {
+	// Helper for getting just the paths out of the search paths
+	getPaths := func(searchPaths []ConfigSearchPath) []string {
+		paths := make([]string, 0, len(searchPaths))
+		for _, sp := range searchPaths {
+			paths = append(paths, sp.Path)
+		}
+
+		return paths
+	}
+
+	// Helper for building a fake environment
+	getEnv := func(vals map[string]string) func(string) string {
+		return func(key string) string {
+			return vals[key]
+		}
+	}
+
+	t.Run("should walk up to the repository root", func(t *testing.T) {
+		// Assemble
+		root := t.TempDir()
+		repo := filepath.Join(root, "repo")
+		cwd := filepath.Join(repo, "services", "billing")
+		assert.Nil(t, os.MkdirAll(cwd, 0755))
+		assert.Nil(t, os.Mkdir(filepath.Join(repo, ".git"), 0755))
+
+		d := configDiscovery{cwd: cwd, getenv: getEnv(nil)}
+
+		// Act
+		paths := getPaths(d.searchPaths())
+
+		// Assert
+		assert.Equal(t, []string{
+			filepath.Join(cwd, ".biome.yaml"),
+			filepath.Join(cwd, ".biome.yml"),
+			filepath.Join(repo, "services", ".biome.yaml"),
+			filepath.Join(repo, "services", ".biome.yml"),
+			filepath.Join(repo, ".biome.yaml"),
+			filepath.Join(repo, ".biome.yml"),
+		}, paths)
+	})
+
+	t.Run("should stop at the ceiling directory", func(t *testing.T) {
+		// Assemble
+		root := t.TempDir()
+		cwd := filepath.Join(root, "a", "b")
+		assert.Nil(t, os.MkdirAll(cwd, 0755))
+
+		d := configDiscovery{cwd: cwd, getenv: getEnv(map[string]string{CONFIG_CEILING_ENV_VAR: filepath.Join(root, "a")})}
+
+		// Act
+		paths := getPaths(d.searchPaths())
+
+		// Assert
+		assert.Equal(t, filepath.Join(root, "a", ".biome.yml"), paths[len(paths)-1])
+		assert.Len(t, paths, 4)
+	})
+
+	t.Run("should search the home, xdg and system directories after the working directory", func(t *testing.T) {
+		// Assemble
+		root := t.TempDir()
+		cwd := filepath.Join(root, "work")
+		home := filepath.Join(root, "home")
+		xdg := filepath.Join(root, "xdg")
+		system := filepath.Join(root, "etc", "biome.d")
+		assert.Nil(t, os.MkdirAll(cwd, 0755))
+		assert.Nil(t, os.MkdirAll(system, 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(system, "global.yaml"), []byte{}, 0644))
+		assert.Nil(t, os.MkdirAll(filepath.Join(xdg, "biome"), 0755))
+		for _, fname := range []string{"b.yaml", "a.yml", "a.local.yml", "notes.txt"} {
+			assert.Nil(t, os.WriteFile(filepath.Join(xdg, "biome", fname), []byte{}, 0644))
+		}
+
+		d := configDiscovery{
+			cwd:    cwd,
+			home:   home,
+			system: system,
+			getenv: getEnv(map[string]string{CONFIG_CEILING_ENV_VAR: cwd, "XDG_CONFIG_HOME": xdg}),
+		}
+
+		// Act
+		searchPaths := d.searchPaths()
+
+		// Assert
+		assert.Equal(t, []string{
+			filepath.Join(cwd, ".biome.yaml"),
+			filepath.Join(cwd, ".biome.yml"),
+			filepath.Join(home, ".biome.yaml"),
+			filepath.Join(home, ".biome.yml"),
+			filepath.Join(xdg, "biome", "a.yml"),
+			filepath.Join(xdg, "biome", "b.yaml"),
+			filepath.Join(system, "global.yaml"),
+		}, getPaths(searchPaths))
+		assert.Equal(t, "home", searchPaths[2].Source)
+		assert.Equal(t, "xdg", searchPaths[4].Source)
+		assert.Equal(t, "system", searchPaths[6].Source)
+	})
+
+	t.Run("should only use the files from the --config flag", func(t *testing.T) {
+		// Assemble
+		d := configDiscovery{
+			explicit: []string{"a.yaml", "b.yaml"},
+			cwd:      t.TempDir(),
+			getenv:   getEnv(map[string]string{CONFIG_ENV_VAR: "c.yaml"}),
+		}
+
+		// Act
+		paths := getPaths(d.searchPaths())
+
+		// Assert
+		assert.Equal(t, []string{"a.yaml", "b.yaml"}, paths)
+	})
+
+	t.Run("should only use the files from the BIOME_CONFIG env var", func(t *testing.T) {
+		// Assemble
+		d := configDiscovery{
+			cwd:    t.TempDir(),
+			getenv: getEnv(map[string]string{CONFIG_ENV_VAR: "c.yaml" + string(os.PathListSeparator) + "d.yaml"}),
+		}
+
+		// Act
+		searchPaths := d.searchPaths()
+
+		// Assert
+		assert.Equal(t, []string{"c.yaml", "d.yaml"}, getPaths(searchPaths))
+		assert.Equal(t, CONFIG_ENV_VAR, searchPaths[0].Source)
+	})
+}
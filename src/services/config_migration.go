@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jeff-roche/biome/src/lib/fileio"
+	"github.com/jeff-roche/biome/src/lib/migrate"
+)
+
+// ConfigMigration is the migration of a single config file to the current config format
+type ConfigMigration struct {
+	File     string
+	Changes  []migrate.Change
+	contents []byte
+	mode     os.FileMode
+}
+
+// Changed will determine if the file needs to be rewritten
+func (m ConfigMigration) Changed() bool {
+	return len(m.Changes) > 0
+}
+
+// Write will save the migrated contents over the config file
+func (m ConfigMigration) Write() error {
+	if !m.Changed() {
+		return nil
+	}
+
+	return os.WriteFile(m.File, m.contents, m.mode)
+}
+
+// MigrateConfig will migrate the files to the current version of the config format without saving them
+// The config files that are found in the search paths are used if no files are given
+func (svc *BiomeConfigurationService) MigrateConfig(files []string) ([]ConfigMigration, error) {
+	if len(files) == 0 {
+		for _, searchPath := range svc.ConfigSearchPaths() {
+			if fileio.FileExists(searchPath.Path) {
+				files = append(files, searchPath.Path)
+			}
+		}
+	}
+
+	migrations := make([]ConfigMigration, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := migrate.Migrate(contents)
+		if err != nil {
+			return nil, fmt.Errorf("unable to migrate %s: %v", file, err)
+		}
+
+		migrations = append(migrations, ConfigMigration{
+			File:     file,
+			Changes:  result.Changes,
+			contents: result.Contents,
+			mode:     info.Mode().Perm(),
+		})
+	}
+
+	return migrations, nil
+}
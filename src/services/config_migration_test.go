@@ -0,0 +1,52 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	t.Run("should migrate the files and only write them when asked", func(t *testing.T) {
+		// Assemble
+		dir := t.TempDir()
+		outdated := filepath.Join(dir, "outdated.yaml")
+		current := filepath.Join(dir, "current.yaml")
+		assert.Nil(t, os.WriteFile(outdated, []byte("name: outdated\n"), 0600))
+		assert.Nil(t, os.WriteFile(current, []byte("version: 2\nname: current\n"), 0644))
+
+		testSvc := &BiomeConfigurationService{}
+
+		// Act
+		migrations, err := testSvc.MigrateConfig([]string{outdated, current})
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Len(t, migrations, 2)
+		assert.True(t, migrations[0].Changed())
+		assert.False(t, migrations[1].Changed())
+
+		contents, _ := os.ReadFile(outdated)
+		assert.Equal(t, "name: outdated\n", string(contents))
+
+		assert.Nil(t, migrations[0].Write())
+		contents, _ = os.ReadFile(outdated)
+		assert.Equal(t, "version: 2\nname: outdated\n", string(contents))
+
+		info, _ := os.Stat(outdated)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("should report files that can't be read", func(t *testing.T) {
+		// Assemble
+		testSvc := &BiomeConfigurationService{}
+
+		// Act
+		_, err := testSvc.MigrateConfig([]string{filepath.Join(t.TempDir(), "missing.yaml")})
+
+		// Assert
+		assert.NotNil(t, err)
+	})
+}
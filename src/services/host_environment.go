@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// defaultAllowedEnvs are the host variables an isolated biome keeps without being told to
+var defaultAllowedEnvs = []string{
+	"PATH",
+	"HOME",
+	"USER",
+	"LOGNAME",
+	"SHELL",
+	"TERM",
+	"TZ",
+	"TMPDIR",
+	"LANG",
+	"LC_*",
+}
+
+// DroppedEnv is a host variable that is removed before the biome is activated
+type DroppedEnv struct {
+	Name     string
+	Reason   string // The unset pattern that matched, or isolate
+	Replaced bool   // The biome sets the variable again
+}
+
+// EnableCleanEnv will isolate the biome from the host environment, even if the biome doesn't ask for it
+func (svc *BiomeConfigurationService) EnableCleanEnv() {
+	svc.cleanEnv = true
+}
+
+// DroppedHostEnvs will work out which host variables are removed when the active biome is activated
+//
+// Variables matching the biome's unset list are always removed. When the biome is isolated (isolate: true
+// or --clean-env) every variable that isn't in the allowlist (the defaults plus allow_env) is removed too
+func (svc *BiomeConfigurationService) DroppedHostEnvs() ([]DroppedEnv, error) {
+	if svc.ActiveBiome == nil {
+		return nil, fmt.Errorf("no biome loaded")
+	}
+
+	isolated := svc.cleanEnv || svc.ActiveBiome.IsIsolated()
+	allowed := append(append([]string{}, defaultAllowedEnvs...), svc.ActiveBiome.AllowEnv...)
+
+	var dropped []DroppedEnv
+	for _, name := range hostEnvNames() {
+		pattern, err := matchEnv(svc.ActiveBiome.Unset, name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unset pattern: %v", err)
+		}
+
+		reason := ""
+		if pattern != "" {
+			reason = "unset " + pattern
+		} else if isolated {
+			keep, err := matchEnv(allowed, name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allow_env pattern: %v", err)
+			}
+
+			if keep == "" {
+				reason = "isolate"
+			}
+		}
+
+		if reason != "" {
+			_, replaced := svc.ActiveBiome.Environment[name]
+			dropped = append(dropped, DroppedEnv{Name: name, Reason: reason, Replaced: replaced})
+		}
+	}
+
+	return dropped, nil
+}
+
+// cleanHostEnv will remove the dropped host variables from the environment so that
+// neither the biome's setters, its commands or the command being run can see them
+func (svc *BiomeConfigurationService) cleanHostEnv() error {
+	dropped, err := svc.DroppedHostEnvs()
+	if err != nil {
+		return err
+	}
+
+	for _, env := range dropped {
+		if err := os.Unsetenv(env.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hostEnvNames will return the names of the host environment variables in alphabetical order
+func hostEnvNames() []string {
+	var names []string
+	for _, env := range os.Environ() {
+		if name, _, found := strings.Cut(env, "="); found && name != "" {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// matchEnv will return the first pattern the variable name matches, or an empty string if none match
+func matchEnv(patterns []string, name string) (string, error) {
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			return "", fmt.Errorf("'%s': %v", pattern, err)
+		}
+
+		if matched {
+			return pattern, nil
+		}
+	}
+
+	return "", nil
+}
+
+// saveHostEnv will save the host environment and return a function that puts it back,
+// used when more than one biome is resolved by the same process
+func saveHostEnv() func() {
+	saved := os.Environ()
+
+	return func() {
+		os.Clearenv()
+
+		for _, env := range saved {
+			if name, val, found := strings.Cut(env, "="); found && name != "" {
+				os.Setenv(name, val)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jeff-roche/biome/src/lib/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostEnvironment(t *testing.T) {
+	// Helper for setting host variables for the length of a test
+	setHostEnvs := func(t *testing.T, envs map[string]string) {
+		for name, val := range envs {
+			os.Setenv(name, val)
+		}
+
+		t.Cleanup(func() {
+			for name := range envs {
+				os.Unsetenv(name)
+			}
+		})
+	}
+
+	// Helper for finding a dropped variable by name
+	findDropped := func(dropped []DroppedEnv, name string) *DroppedEnv {
+		for i := range dropped {
+			if dropped[i].Name == name {
+				return &dropped[i]
+			}
+		}
+
+		return nil
+	}
+
+	t.Run("DroppedHostEnvs", func(t *testing.T) {
+		t.Run("should drop the variables matching the unset list", func(t *testing.T) {
+			// Assemble
+			setHostEnvs(t, map[string]string{"BIOME_TEST_AWS_PROFILE": "dev", "BIOME_TEST_KEEP": "keep"})
+			testSvc := &BiomeConfigurationService{
+				ActiveBiome: &types.BiomeConfig{Name: "test", Unset: types.StringList{"BIOME_TEST_AWS_*"}},
+			}
+
+			// Act
+			dropped, err := testSvc.DroppedHostEnvs()
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, []DroppedEnv{{Name: "BIOME_TEST_AWS_PROFILE", Reason: "unset BIOME_TEST_AWS_*"}}, dropped)
+		})
+
+		t.Run("should only keep the allowed variables when isolated", func(t *testing.T) {
+			// Assemble
+			setHostEnvs(t, map[string]string{"BIOME_TEST_KUBECONFIG": "host", "BIOME_TEST_SSH_SOCK": "sock"})
+			testSvc := &BiomeConfigurationService{
+				ActiveBiome: &types.BiomeConfig{
+					Name:        "test",
+					AllowEnv:    types.StringList{"BIOME_TEST_SSH_*"},
+					Environment: map[string]interface{}{"BIOME_TEST_KUBECONFIG": "biome"},
+				},
+			}
+			testSvc.EnableCleanEnv()
+
+			// Act
+			dropped, err := testSvc.DroppedHostEnvs()
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, &DroppedEnv{Name: "BIOME_TEST_KUBECONFIG", Reason: "isolate", Replaced: true}, findDropped(dropped, "BIOME_TEST_KUBECONFIG"))
+			assert.Nil(t, findDropped(dropped, "BIOME_TEST_SSH_SOCK"))
+			assert.Nil(t, findDropped(dropped, "PATH"))
+		})
+
+		t.Run("should report invalid patterns", func(t *testing.T) {
+			// Assemble
+			testSvc := &BiomeConfigurationService{
+				ActiveBiome: &types.BiomeConfig{Name: "test", Unset: types.StringList{"AWS_["}},
+			}
+
+			// Act
+			_, err := testSvc.DroppedHostEnvs()
+
+			// Assert
+			assert.NotNil(t, err)
+			assert.ErrorContains(t, err, "invalid unset pattern: 'AWS_['")
+		})
+	})
+
+	t.Run("ActivateBiome", func(t *testing.T) {
+		t.Run("should remove the dropped variables before the biome is configured", func(t *testing.T) {
+			// Assemble
+			setHostEnvs(t, map[string]string{"BIOME_TEST_TOKEN": "leaked", "BIOME_TEST_OTHER": "other"})
+			isolate := true
+			testSvc := &BiomeConfigurationService{
+				ActiveBiome: &types.BiomeConfig{
+					Name:        "test",
+					Isolate:     &isolate,
+					AllowEnv:    types.StringList{"BIOME_TEST_OTHER"},
+					Environment: map[string]interface{}{"BIOME_TEST_COPY": "${BIOME_TEST_OTHER}"},
+				},
+				configuredEnvs: map[string]string{},
+			}
+
+			// Isolating clears the test process environment so put it back afterwards
+			hostEnv := os.Environ()
+			t.Cleanup(func() {
+				os.Clearenv()
+				for _, env := range hostEnv {
+					if name, val, found := strings.Cut(env, "="); found {
+						os.Setenv(name, val)
+					}
+				}
+			})
+
+			// Act
+			err := testSvc.ActivateBiome()
+
+			// Assert
+			assert.Nil(t, err)
+			_, exists := os.LookupEnv("BIOME_TEST_TOKEN")
+			assert.False(t, exists)
+			assert.Equal(t, "other", os.Getenv("BIOME_TEST_COPY"))
+		})
+	})
+}